@@ -0,0 +1,98 @@
+package qpay
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// amountLimitFields are the body fields QPay has been seen to report amount
+// limits in, by error code.
+var amountLimitFields = map[string][]string{
+	ErrMinAmountErr: {"min_amount", "minimum_amount"},
+	ErrMaxAmountErr: {"max_amount", "maximum_amount"},
+}
+
+// amountLimitInMessage matches an amount such as "1000", "1,000" or "1000.50"
+// shortly after a limit keyword, by error code: "minimum 1000", "max: 5000",
+// "at least 1,000.50". Other numbers in a message, such as the submitted
+// amount, are not limits.
+var amountLimitInMessage = map[string]*regexp.Regexp{
+	ErrMinAmountErr: regexp.MustCompile(`(?i)\b(?:minimum|min|at least)\b[^\d]{0,20}?(\d[\d,]*(?:\.\d+)?)`),
+	ErrMaxAmountErr: regexp.MustCompile(`(?i)\b(?:maximum|max|at most)\b[^\d]{0,20}?(\d[\d,]*(?:\.\d+)?)`),
+}
+
+// parseAmountLimit sets MinAmount or MaxAmount for MIN_AMOUNT_ERR and
+// MAX_AMOUNT_ERR errors, from a limit field in the body or, failing that, an
+// amount following a min or max keyword in the message. Both stay 0 when
+// neither has one.
+func (e *Error) parseAmountLimit(body []byte) {
+	fields, ok := amountLimitFields[e.Code]
+	if !ok {
+		return
+	}
+	limit, ok := amountLimitFromBody(body, fields)
+	if !ok {
+		limit, ok = amountLimitFromMessage(amountLimitInMessage[e.Code], e.Message)
+	}
+	if !ok {
+		return
+	}
+	if e.Code == ErrMinAmountErr {
+		e.MinAmount = limit
+	} else {
+		e.MaxAmount = limit
+	}
+}
+
+func amountLimitFromBody(body []byte, fields []string) (float64, bool) {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return 0, false
+	}
+	for _, field := range fields {
+		v, ok := raw[field]
+		if !ok {
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(v, &n); err != nil {
+			var s string
+			if json.Unmarshal(v, &s) != nil {
+				continue
+			}
+			n = json.Number(strings.TrimSpace(s))
+		}
+		if f, err := n.Float64(); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+func amountLimitFromMessage(re *regexp.Regexp, msg string) (float64, bool) {
+	m := re.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	return f, err == nil
+}
+
+// IsAmountTooLow reports whether err is QPay's MIN_AMOUNT_ERR. The minimum,
+// when QPay reported one, is in the *Error's MinAmount. Wrapped errors are
+// unwrapped.
+func IsAmountTooLow(err error) bool {
+	var qErr *Error
+	return errors.As(err, &qErr) && qErr.Code == ErrMinAmountErr
+}
+
+// IsAmountTooHigh reports whether err is QPay's MAX_AMOUNT_ERR. The maximum,
+// when QPay reported one, is in the *Error's MaxAmount. Wrapped errors are
+// unwrapped.
+func IsAmountTooHigh(err error) bool {
+	var qErr *Error
+	return errors.As(err, &qErr) && qErr.Code == ErrMaxAmountErr
+}
@@ -0,0 +1,69 @@
+package qpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDecodeError_AmountLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		min, max float64
+	}{
+		{"min field", `{"error":"MIN_AMOUNT_ERR","message":"too low","min_amount":1000}`, 1000, 0},
+		{"min string field", `{"error":"MIN_AMOUNT_ERR","message":"too low","minimum_amount":"500.5"}`, 500.5, 0},
+		{"max field", `{"error":"MAX_AMOUNT_ERR","message":"too high","max_amount":5000000}`, 0, 5000000},
+		{"min in message", `{"error":"MIN_AMOUNT_ERR","message":"Amount must be at least 1,000.50 MNT"}`, 1000.5, 0},
+		{"max in message", `{"error":"MAX_AMOUNT_ERR","message":"Maximum amount is 10000000"}`, 0, 10000000},
+		{"no limit", `{"error":"MIN_AMOUNT_ERR","message":"Amount is too low"}`, 0, 0},
+		{"submitted amount first", `{"error":"MIN_AMOUNT_ERR","message":"Amount 50 is below the minimum of 100"}`, 100, 0},
+		{"max after submitted amount", `{"error":"MAX_AMOUNT_ERR","message":"Amount 12,000,000 exceeds max: 10,000,000"}`, 0, 10000000},
+		{"number without keyword", `{"error":"MIN_AMOUNT_ERR","message":"Amount 50 is too low"}`, 0, 0},
+		{"other code", `{"error":"INVALID_AMOUNT","message":"min 1000","min_amount":1000}`, 0, 0},
+	}
+	for _, tt := range tests {
+		qErr := decodeError(http.StatusBadRequest, []byte(tt.body))
+		if qErr.MinAmount != tt.min || qErr.MaxAmount != tt.max {
+			t.Errorf("%s: expected min %v max %v, got min %v max %v", tt.name, tt.min, tt.max, qErr.MinAmount, qErr.MaxAmount)
+		}
+	}
+}
+
+func TestIsAmountTooLowAndTooHigh(t *testing.T) {
+	low := &Error{StatusCode: 400, Code: ErrMinAmountErr, MinAmount: 1000}
+	high := fmt.Errorf("create: %w", &Error{StatusCode: 400, Code: ErrMaxAmountErr})
+
+	if !IsAmountTooLow(low) || IsAmountTooHigh(low) {
+		t.Error("expected MIN_AMOUNT_ERR to be too low only")
+	}
+	if !IsAmountTooHigh(high) || IsAmountTooLow(high) {
+		t.Error("expected wrapped MAX_AMOUNT_ERR to be too high only")
+	}
+	if IsAmountTooLow(errors.New("MIN_AMOUNT_ERR")) || IsAmountTooHigh(nil) {
+		t.Error("expected non-QPay errors not to match")
+	}
+}
+
+func TestCreateSimpleInvoice_AmountTooLow(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"MIN_AMOUNT_ERR","message":"Minimum amount is 100"}`))
+	})
+	defer server.Close()
+
+	_, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{
+		SenderInvoiceNo:     "SINV-001",
+		InvoiceReceiverCode: "terminal",
+		InvoiceDescription:  "Simple test",
+		Amount:              10,
+		CallbackURL:         "https://example.com/callback",
+	})
+	var qErr *Error
+	if !IsAmountTooLow(err) || !errors.As(err, &qErr) || qErr.MinAmount != 100 {
+		t.Fatalf("expected MIN_AMOUNT_ERR with minimum 100, got %v", err)
+	}
+}
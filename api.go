@@ -0,0 +1,26 @@
+package qpay
+
+import "context"
+
+// API is the set of QPay operations a Client performs. Depend on API instead
+// of *Client in code you want to unit test; package qpaytest provides a
+// recording implementation.
+type API interface {
+	Ping(ctx context.Context) error
+
+	CreateInvoice(ctx context.Context, req *CreateInvoiceRequest) (*InvoiceResponse, error)
+	CreateSimpleInvoice(ctx context.Context, req *CreateSimpleInvoiceRequest) (*InvoiceResponse, error)
+	CreateEbarimtInvoice(ctx context.Context, req *CreateEbarimtInvoiceRequest) (*InvoiceResponse, error)
+	CancelInvoice(ctx context.Context, invoiceID string) error
+
+	GetPayment(ctx context.Context, paymentID string) (*PaymentDetail, error)
+	CheckPayment(ctx context.Context, req *PaymentCheckRequest) (*PaymentCheckResponse, error)
+	ListPayments(ctx context.Context, req *PaymentListRequest) (*PaymentListResponse, error)
+	CancelPayment(ctx context.Context, paymentID string, req *PaymentCancelRequest) error
+	RefundPayment(ctx context.Context, paymentID string, req *PaymentRefundRequest) error
+
+	CreateEbarimt(ctx context.Context, req *CreateEbarimtRequest) (*EbarimtResponse, error)
+	CancelEbarimt(ctx context.Context, paymentID string) (*EbarimtResponse, error)
+}
+
+var _ API = (*Client)(nil)
@@ -1,9 +1,16 @@
 package qpay
 
-import "context"
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+)
 
 // GetToken authenticates with QPay using Basic Auth and returns a new token pair.
-// The token is automatically stored in the client for subsequent requests.
+// The token is automatically stored in the client for subsequent requests made
+// with the same credentials (see WithCredentials).
 func (c *Client) GetToken(ctx context.Context) (*TokenResponse, error) {
 	token, err := c.getTokenRequest(ctx)
 	if err != nil {
@@ -11,17 +18,31 @@ func (c *Client) GetToken(ctx context.Context) (*TokenResponse, error) {
 	}
 
 	c.mu.Lock()
-	c.storeToken(token)
+	c.session(ctx).storeToken(token, c.now())
 	c.mu.Unlock()
 
 	return token, nil
 }
 
+// FetchToken authenticates with QPay using Basic Auth and returns a new token pair
+// without storing it in the client. The client's current token is left untouched.
+func (c *Client) FetchToken(ctx context.Context) (*TokenResponse, error) {
+	return c.getTokenRequest(ctx)
+}
+
+// Ping verifies that QPay is reachable and the configured credentials are accepted.
+// It performs a token fetch via FetchToken and does not modify the client's token.
+// A rejected authentication is returned as *Error.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.FetchToken(ctx)
+	return err
+}
+
 // RefreshToken uses the current refresh token to obtain a new access token.
 // The new token is automatically stored in the client for subsequent requests.
 func (c *Client) RefreshToken(ctx context.Context) (*TokenResponse, error) {
 	c.mu.Lock()
-	refreshTok := c.refreshToken
+	refreshTok := c.session(ctx).refreshToken
 	c.mu.Unlock()
 
 	token, err := c.doRefreshTokenHTTP(ctx, refreshTok)
@@ -30,16 +51,117 @@ func (c *Client) RefreshToken(ctx context.Context) (*TokenResponse, error) {
 	}
 
 	c.mu.Lock()
-	c.storeToken(token)
+	c.session(ctx).storeToken(token, c.now())
 	c.mu.Unlock()
 
 	return token, nil
 }
 
+// tokenProbePath is what ValidateToken requests: a payment that doesn't exist,
+// so an accepted token gets a cheap not-found answer and changes nothing.
+const tokenProbePath = "/payment/qpay-go-token-probe"
+
+// ValidateToken confirms that QPay still accepts the access token for the
+// credentials carried by ctx, catching a server-side revocation that the local
+// expiry check can't see, e.g. before a long batch job. QPay has no token
+// introspection endpoint, so it sends a cheap authenticated request for a
+// payment that doesn't exist: any answer but 401 or an expired-token error
+// means the token was accepted.
+//
+// A rejected token is discarded and replaced, by refresh or full
+// authentication as for any request, and the new token is checked the same
+// way. ValidateToken returns nil when the client ends up holding a token QPay
+// accepts; otherwise it returns the rejection as *Error, or a token or
+// transport error. 5xx answers are returned as errors, since they don't show
+// whether the token was accepted.
+func (c *Client) ValidateToken(ctx context.Context) error {
+	rejected, err := c.probeToken(ctx)
+	if !rejected {
+		return err
+	}
+
+	c.mu.Lock()
+	c.session(ctx).invalidate(false)
+	c.mu.Unlock()
+	c.tokenEvent(ctx, TokenEventInvalidate, err)
+
+	_, err = c.probeToken(ctx)
+	return err
+}
+
+// probeToken sends the ValidateToken request, returning a nil error for any
+// answer that shows the token was accepted. rejected reports that QPay refused
+// the token, as opposed to failing to issue one.
+func (c *Client) probeToken(ctx context.Context) (rejected bool, err error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	authorization, err := c.authorization(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.config.BaseURL+c.apiPath(tokenProbePath), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	c.applyURLRewriter(req)
+
+	resp, respBody, err := c.send(req)
+	if err != nil {
+		return false, err
+	}
+	qErr := responseError(resp, respBody)
+	if qErr == nil {
+		return false, nil
+	}
+	rejected = qErr.StatusCode == http.StatusUnauthorized || IsTokenExpired(qErr)
+	if !rejected && qErr.StatusCode < http.StatusInternalServerError {
+		return false, nil
+	}
+	c.recordError(OpAuthValidate, qErr)
+	return rejected, qErr
+}
+
 func (c *Client) getTokenRequest(ctx context.Context) (*TokenResponse, error) {
+	token, err := c.authTokenRequest(ctx)
+	c.tokenEvent(ctx, TokenEventAuth, err)
+	return token, err
+}
+
+func (c *Client) authTokenRequest(ctx context.Context) (*TokenResponse, error) {
 	var token TokenResponse
-	if err := c.doBasicAuthRequest(ctx, "POST", "/v2/auth/token", &token); err != nil {
+	if err := c.doBasicAuthRequest(ctx, "POST", c.apiPath("/auth/token"), &token); err != nil {
+		c.recordError(OpAuthToken, err)
+		return nil, err
+	}
+	if err := token.validate(); err != nil {
 		return nil, err
 	}
 	return &token, nil
 }
+
+// WithAuthBody sends body with the given Content-Type on token requests, in
+// addition to Basic Auth, for QPay-compatible gateways that expect one. By
+// default the token request has no body.
+func WithAuthBody(contentType string, body []byte) Option {
+	return func(c *Client) {
+		c.authContentType = contentType
+		c.authBody = append([]byte(nil), body...)
+	}
+}
+
+// WithAuthForm sends form, URL-encoded, as the token request body, e.g.
+// url.Values{"grant_type": {"client_credentials"}}. See WithAuthBody.
+func WithAuthForm(form url.Values) Option {
+	return WithAuthBody("application/x-www-form-urlencoded", []byte(form.Encode()))
+}
+
+// BasicAuthHeader returns the Authorization header value the client sends on
+// token requests: "Basic " and the base64 of username:password, as in RFC
+// 7617. The client builds its own header with it, so a gateway fetching tokens
+// on the SDK's behalf can reproduce the request exactly.
+func BasicAuthHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -3,8 +3,12 @@ package qpay
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -214,3 +218,328 @@ func TestRefreshToken_Error(t *testing.T) {
 		t.Errorf("expected status 401, got %d", qErr.StatusCode)
 	}
 }
+
+func TestFetchToken_DoesNotStoreToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:      "fetched-access",
+			RefreshToken:     "fetched-refresh",
+			ExpiresIn:        time.Now().Unix() + 3600,
+			RefreshExpiresIn: time.Now().Unix() + 7200,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "user",
+		Password: "pass",
+	}, server.Client())
+	client.accessToken = "live-access"
+	client.refreshToken = "live-refresh"
+
+	token, err := client.FetchToken(context.Background())
+	if err != nil {
+		t.Fatalf("FetchToken failed: %v", err)
+	}
+	if token.AccessToken != "fetched-access" {
+		t.Errorf("expected access token 'fetched-access', got %q", token.AccessToken)
+	}
+	if client.accessToken != "live-access" {
+		t.Errorf("live access token was modified: got %q", client.accessToken)
+	}
+	if client.refreshToken != "live-refresh" {
+		t.Errorf("live refresh token was modified: got %q", client.refreshToken)
+	}
+}
+
+func TestPing_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/auth/token" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken: "access",
+			ExpiresIn:   time.Now().Unix() + 3600,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "user",
+		Password: "pass",
+	}, server.Client())
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if client.accessToken != "" {
+		t.Errorf("Ping should not store a token, got %q", client.accessToken)
+	}
+}
+
+func TestPing_AuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "AUTHENTICATION_FAILED",
+			"message": "Invalid credentials",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "user",
+		Password: "wrong",
+	}, server.Client())
+
+	err := client.Ping(context.Background())
+	qErr, ok := IsQPayError(err)
+	if !ok {
+		t.Fatalf("expected QPay error, got %T: %v", err, err)
+	}
+	if qErr.Code != ErrAuthenticationFailed {
+		t.Errorf("expected code %q, got %q", ErrAuthenticationFailed, qErr.Code)
+	}
+}
+
+func TestGetToken_EmptyTokenResponse(t *testing.T) {
+	tests := map[string]string{
+		"empty body":      `{}`,
+		"no expiry":       `{"access_token":"access-abc"}`,
+		"negative expiry": `{"access_token":"access-abc","expires_in":-5}`,
+	}
+	for name, body := range tests {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Write([]byte(body))
+		}))
+
+		client := NewClientWithHTTPClient(&Config{
+			BaseURL:  server.URL,
+			Username: "user",
+			Password: "pass",
+		}, server.Client())
+
+		_, err := client.GetPayment(context.Background(), "PAY-1")
+		server.Close()
+		if !errors.Is(err, ErrInvalidTokenResponse) {
+			t.Errorf("%s: expected ErrInvalidTokenResponse, got %v", name, err)
+		}
+		if calls != 1 {
+			t.Errorf("%s: expected a single auth request, got %d", name, calls)
+		}
+		if client.accessToken != "" {
+			t.Errorf("%s: expected no token to be stored", name)
+		}
+	}
+}
+
+func TestRefreshToken_EmptyTokenResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "user",
+		Password: "pass",
+	}, server.Client())
+	client.refreshToken = "refresh-xyz"
+
+	_, err := client.RefreshToken(context.Background())
+	if !errors.Is(err, ErrInvalidTokenResponse) {
+		t.Fatalf("expected ErrInvalidTokenResponse, got %v", err)
+	}
+}
+
+func TestGetToken_DefaultSendsNoBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if len(data) != 0 || r.Header.Get("Content-Type") != "" {
+			t.Errorf("expected no body, got %q with Content-Type %q", data, r.Header.Get("Content-Type"))
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access-abc", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+	if _, err := client.GetToken(context.Background()); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+}
+
+func TestWithAuthForm(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("expected Basic Auth to be kept, got %q %q %v", user, pass, ok)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+			t.Errorf("unexpected Content-Type %q", ct)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("expected grant_type client_credentials, got %q", got)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access-abc", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithAuthForm(url.Values{"grant_type": {"client_credentials"}}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+	if _, err := client.GetToken(context.Background()); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected the body to be resent on retry, got %d attempts", attempts)
+	}
+}
+
+func TestWithAuthBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if string(data) != `{"grant_type":"password"}` || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected body %q with Content-Type %q", data, r.Header.Get("Content-Type"))
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access-abc", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	body := []byte(`{"grant_type":"password"}`)
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithAuthBody("application/json", body))
+	body[0] = 'X' // the option keeps its own copy
+
+	if _, err := client.GetToken(context.Background()); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+}
+
+func TestBasicAuthHeader(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://example.invalid", nil)
+	req.SetBasicAuth("merchant", "p:ss wörd")
+	if got, want := BasicAuthHeader("merchant", "p:ss wörd"), req.Header.Get("Authorization"); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "tok", ExpiresIn: time.Now().Unix() + 3600})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"})
+	if _, err := client.GetToken(context.Background()); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+	if sent != BasicAuthHeader("user", "pass") {
+		t.Errorf("client sent %q", sent)
+	}
+}
+
+// newProbeServer answers ValidateToken probes with 404 for accepted tokens
+// and 401 for the access tokens in revoked.
+func newProbeServer(t *testing.T, revoked map[string]bool, tokens *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/auth/token", "/v2/auth/refresh":
+			n := atomic.AddInt32(tokens, 1)
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken:      "access-" + string(rune('0'+n)),
+				RefreshToken:     "refresh",
+				ExpiresIn:        3600,
+				RefreshExpiresIn: 7200,
+			})
+		case "/v2" + tokenProbePath:
+			if revoked[r.Header.Get("Authorization")] {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": ErrPaymentNotFound})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestValidateToken_Accepted(t *testing.T) {
+	var tokens int32
+	server := newProbeServer(t, nil, &tokens)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(), WithLastErrors())
+	if err := client.ValidateToken(context.Background()); err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if err := client.ValidateToken(context.Background()); err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if tokens != 1 {
+		t.Errorf("expected the token to be reused, got %d token requests", tokens)
+	}
+	if got := client.LastError(OpAuthValidate); got != nil {
+		t.Errorf("expected no recorded error, got %v", got)
+	}
+}
+
+func TestValidateToken_RevokedIsReplaced(t *testing.T) {
+	var tokens int32
+	server := newProbeServer(t, map[string]bool{"Bearer access-1": true}, &tokens)
+	defer server.Close()
+
+	var events []TokenEventType
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithTokenEventHook(func(ev TokenEvent) { events = append(events, ev.Type) }))
+	if err := client.ValidateToken(context.Background()); err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if client.accessToken != "access-2" {
+		t.Errorf("expected the revoked token to be replaced, got %q", client.accessToken)
+	}
+	if len(events) != 3 || events[1] != TokenEventInvalidate || events[2] != TokenEventRefresh {
+		t.Errorf("expected auth, invalidate and refresh events, got %v", events)
+	}
+}
+
+func TestValidateToken_StillRejected(t *testing.T) {
+	var tokens int32
+	server := newProbeServer(t, map[string]bool{"Bearer access-1": true, "Bearer access-2": true}, &tokens)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+	err := client.ValidateToken(context.Background())
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.StatusCode != http.StatusUnauthorized || qErr.Operation != OpAuthValidate {
+		t.Fatalf("expected a 401 *Error for auth.validate, got %v", err)
+	}
+	if tokens != 2 {
+		t.Errorf("expected one replacement token, got %d token requests", tokens)
+	}
+}
+
+func TestValidateToken_ServerError(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+
+	if err := client.ValidateToken(context.Background()); err == nil {
+		t.Error("expected a 5xx answer to be an error")
+	}
+}
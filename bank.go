@@ -0,0 +1,62 @@
+package qpay
+
+import "strings"
+
+// Bank is a Mongolian bank as identified by the six-digit codes QPay uses in
+// account_bank_code and transaction_bank_code fields.
+type Bank struct {
+	Code      string
+	Name      string
+	ShortName string
+}
+
+// banks lists the interbank codes QPay accepts and returns. Codes not listed
+// here still work with the API; callers can fall back to the name QPay sends,
+// such as P2PTransaction.AccountBankName.
+var banks = []Bank{
+	{Code: "010000", Name: "Bank of Mongolia", ShortName: "Mongolbank"},
+	{Code: "040000", Name: "Trade and Development Bank", ShortName: "TDB"},
+	{Code: "050000", Name: "Khan Bank", ShortName: "Khan"},
+	{Code: "150000", Name: "Golomt Bank", ShortName: "Golomt"},
+	{Code: "190000", Name: "Transport and Development Bank", ShortName: "Trans"},
+	{Code: "210000", Name: "Arig Bank", ShortName: "Arig"},
+	{Code: "220000", Name: "Credit Bank", ShortName: "Credit"},
+	{Code: "290000", Name: "National Investment Bank", ShortName: "NIB"},
+	{Code: "300000", Name: "Capitron Bank", ShortName: "Capitron"},
+	{Code: "320000", Name: "Xac Bank", ShortName: "Xac"},
+	{Code: "330000", Name: "Chinggis Khaan Bank", ShortName: "CKB"},
+	{Code: "340000", Name: "State Bank", ShortName: "State"},
+	{Code: "360000", Name: "Development Bank of Mongolia", ShortName: "DBM"},
+	{Code: "380000", Name: "Bogd Bank", ShortName: "Bogd"},
+	{Code: "900000", Name: "State Treasury", ShortName: "Treasury"},
+}
+
+var banksByCode = func() map[string]Bank {
+	m := make(map[string]Bank, len(banks))
+	for _, b := range banks {
+		m[b.Code] = b
+	}
+	return m
+}()
+
+// BankByCode returns the bank with the given code, ignoring surrounding
+// whitespace.
+func BankByCode(code string) (Bank, bool) {
+	b, ok := banksByCode[strings.TrimSpace(code)]
+	return b, ok
+}
+
+// Banks returns the known banks ordered by code.
+func Banks() []Bank {
+	return append([]Bank(nil), banks...)
+}
+
+// AccountBank returns the bank the payment was received into.
+func (t *P2PTransaction) AccountBank() (Bank, bool) {
+	return BankByCode(t.AccountBankCode)
+}
+
+// TransactionBank returns the bank the payer paid from.
+func (t *P2PTransaction) TransactionBank() (Bank, bool) {
+	return BankByCode(t.TransactionBankCode)
+}
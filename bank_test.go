@@ -0,0 +1,48 @@
+package qpay
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestBankByCode(t *testing.T) {
+	b, ok := BankByCode(" 050000 ")
+	if !ok || b.ShortName != "Khan" || b.Code != "050000" {
+		t.Errorf("expected Khan Bank, got %+v, %v", b, ok)
+	}
+	if _, ok := BankByCode("999999"); ok {
+		t.Error("expected unknown code to miss")
+	}
+}
+
+func TestBanks(t *testing.T) {
+	list := Banks()
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Code < list[j].Code }) {
+		t.Error("expected banks ordered by code")
+	}
+	seen := make(map[string]bool)
+	for _, b := range list {
+		if len(b.Code) != 6 || !isDigits(b.Code) || b.Name == "" || b.ShortName == "" {
+			t.Errorf("malformed bank %+v", b)
+		}
+		if seen[b.Code] {
+			t.Errorf("duplicate code %s", b.Code)
+		}
+		seen[b.Code] = true
+	}
+
+	list[0].Name = "changed"
+	if Banks()[0].Name == "changed" {
+		t.Error("Banks should return a copy")
+	}
+}
+
+func TestP2PTransaction_Banks(t *testing.T) {
+	tx := P2PTransaction{TransactionBankCode: "150000", AccountBankCode: "040000"}
+	if b, ok := tx.TransactionBank(); !ok || b.ShortName != "Golomt" {
+		t.Errorf("expected Golomt, got %+v", b)
+	}
+	if b, ok := tx.AccountBank(); !ok || b.ShortName != "TDB" {
+		t.Errorf("expected TDB, got %+v", b)
+	}
+}
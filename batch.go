@@ -0,0 +1,89 @@
+package qpay
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency is used by batch methods when concurrency is below 1.
+const DefaultBatchConcurrency = 4
+
+// runBatch calls fn for each index in [0, n) with at most concurrency calls in
+// flight and returns the per-index errors. Once ctx is done, indexes that have
+// not started yet fail with ctx.Err() instead of calling fn.
+func runBatch(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) []error {
+	if concurrency < 1 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < n; j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// CancelPaymentsBatch cancels card payments concurrently, running at most
+// concurrency requests at a time (DefaultBatchConcurrency if below 1). Every
+// call shares req. The returned map has an entry for each distinct ID, nil on
+// success. Payments not yet attempted when ctx is done report ctx.Err().
+func (c *Client) CancelPaymentsBatch(ctx context.Context, paymentIDs []string, req *PaymentCancelRequest, concurrency int) map[string]error {
+	ids := make([]string, 0, len(paymentIDs))
+	seen := make(map[string]bool, len(paymentIDs))
+	for _, id := range paymentIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	errs := runBatch(ctx, len(ids), concurrency, func(ctx context.Context, i int) error {
+		return c.CancelPayment(ctx, ids[i], req)
+	})
+
+	result := make(map[string]error, len(ids))
+	for i, id := range ids {
+		result[id] = errs[i]
+	}
+	return result
+}
+
+// CreateEbarimtsBatch creates ebarimt receipts concurrently, e.g. for a day's
+// paid payments, running at most concurrency requests at a time
+// (DefaultBatchConcurrency if below 1). The results line up with reqs: the
+// receipt is nil where the error is not. Requests not yet started when ctx is
+// done report ctx.Err(). Each call goes through the client's rate limiter and
+// retry policy as CreateEbarimt would.
+func (c *Client) CreateEbarimtsBatch(ctx context.Context, reqs []*CreateEbarimtRequest, concurrency int) ([]*EbarimtResponse, []error) {
+	receipts := make([]*EbarimtResponse, len(reqs))
+	errs := runBatch(ctx, len(reqs), concurrency, func(ctx context.Context, i int) error {
+		resp, err := c.CreateEbarimt(ctx, reqs[i])
+		receipts[i] = resp
+		return err
+	})
+	return receipts, errs
+}
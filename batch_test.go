@@ -0,0 +1,207 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancelPaymentsBatch_Success(t *testing.T) {
+	var mu sync.Mutex
+	canceled := map[string]string{}
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("expected DELETE, got %s", r.Method)
+		}
+		var req PaymentCancelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		id := strings.TrimPrefix(r.URL.Path, "/v2/payment/cancel/")
+		mu.Lock()
+		canceled[id] = req.Note
+		mu.Unlock()
+
+		if id == "pay-bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":   ErrPaymentAlreadyCanceled,
+				"message": "Payment already canceled",
+			})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	ids := []string{"pay-1", "pay-2", "pay-bad", "pay-1"}
+	results := client.CancelPaymentsBatch(context.Background(), ids, &PaymentCancelRequest{Note: "chargeback"}, 2)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d: %v", len(results), results)
+	}
+	if results["pay-1"] != nil || results["pay-2"] != nil {
+		t.Errorf("expected success for pay-1 and pay-2, got %v", results)
+	}
+	qErr, ok := IsQPayError(results["pay-bad"])
+	if !ok || qErr.Code != ErrPaymentAlreadyCanceled {
+		t.Errorf("expected PAYMENT_ALREADY_CANCELED for pay-bad, got %v", results["pay-bad"])
+	}
+	for id, note := range canceled {
+		if note != "chargeback" {
+			t.Errorf("%s: expected shared note 'chargeback', got %q", id, note)
+		}
+	}
+	if len(canceled) != 3 {
+		t.Errorf("expected 3 cancel requests, got %d", len(canceled))
+	}
+}
+
+func TestCancelPaymentsBatch_BoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	})
+	defer server.Close()
+
+	// Fetch the token up front so the concurrent calls only hit the cancel endpoint.
+	if err := client.ensureToken(context.Background()); err != nil {
+		t.Fatalf("ensureToken failed: %v", err)
+	}
+
+	ids := []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"}
+	results := client.CancelPaymentsBatch(context.Background(), ids, nil, 3)
+
+	for id, err := range results {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", id, err)
+		}
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", max)
+	}
+}
+
+func TestCancelPaymentsBatch_ContextCanceled(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.CancelPaymentsBatch(ctx, []string{"p1", "p2", "p3"}, nil, 1)
+
+	for id, err := range results {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", id, err)
+		}
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("expected no requests after cancellation, got %d", calls)
+	}
+}
+
+func TestRunBatch_DefaultConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	errs := runBatch(context.Background(), 10, 0, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		if i == 7 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if len(errs) != 10 {
+		t.Fatalf("expected 10 results, got %d", len(errs))
+	}
+	for i, err := range errs {
+		if (i == 7) != (err != nil) {
+			t.Errorf("index %d: unexpected error state %v", i, err)
+		}
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > DefaultBatchConcurrency {
+		t.Errorf("expected at most %d concurrent calls, got %d", DefaultBatchConcurrency, max)
+	}
+}
+
+func TestCreateEbarimtsBatch(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req CreateEbarimtRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.PaymentID == "pay-bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": ErrPaymentNotPaid})
+			return
+		}
+		json.NewEncoder(w).Encode(EbarimtResponse{ID: "eb-" + req.PaymentID})
+	})
+	defer server.Close()
+
+	reqs := []*CreateEbarimtRequest{
+		{PaymentID: "pay-1", EbarimtReceiverType: EbarimtReceiverCitizen},
+		{PaymentID: "pay-bad", EbarimtReceiverType: EbarimtReceiverCitizen},
+		{PaymentID: "pay-3", EbarimtReceiverType: EbarimtReceiverOrganization, EbarimtReceiver: "12"},
+		{PaymentID: "pay-4", EbarimtReceiverType: EbarimtReceiverCitizen},
+	}
+	receipts, errs := client.CreateEbarimtsBatch(context.Background(), reqs, 2)
+
+	if len(receipts) != 4 || len(errs) != 4 {
+		t.Fatalf("expected 4 results, got %d receipts and %d errors", len(receipts), len(errs))
+	}
+	for _, i := range []int{0, 3} {
+		if errs[i] != nil || receipts[i] == nil || receipts[i].ID != "eb-"+reqs[i].PaymentID {
+			t.Errorf("reqs[%d]: expected receipt for %s, got %+v, %v", i, reqs[i].PaymentID, receipts[i], errs[i])
+		}
+	}
+	if qErr, ok := IsQPayError(errs[1]); !ok || qErr.Code != ErrPaymentNotPaid || receipts[1] != nil {
+		t.Errorf("expected PAYMENT_NOT_PAID for reqs[1], got %+v, %v", receipts[1], errs[1])
+	}
+	var vErr *ValidationError
+	if !errors.As(errs[2], &vErr) || receipts[2] != nil {
+		t.Errorf("expected *ValidationError for reqs[2], got %+v, %v", receipts[2], errs[2])
+	}
+}
+
+func TestCreateEbarimtsBatch_Canceled(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	receipts, errs := client.CreateEbarimtsBatch(ctx, []*CreateEbarimtRequest{{PaymentID: "pay-1"}, {PaymentID: "pay-2"}}, 1)
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) || receipts[i] != nil {
+			t.Errorf("reqs[%d]: expected context.Canceled, got %+v, %v", i, receipts[i], err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected no requests, got %d", calls)
+	}
+}
@@ -0,0 +1,49 @@
+package qpay
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// CanonicalJSON encodes v as JSON with every object's keys sorted, no
+// insignificant whitespace and numbers kept exactly as encoded. Two values that
+// marshal to equal JSON documents produce identical bytes, so a signature over
+// the output is reproducible regardless of struct field order or map contents.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	// encoding/json writes map keys in sorted order.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// WithCanonicalJSON makes the client encode request bodies with CanonicalJSON,
+// for setups that sign requests before they reach QPay.
+func WithCanonicalJSON() Option {
+	return func(c *Client) {
+		c.canonicalJSON = true
+	}
+}
+
+// marshalBody encodes a request body, canonically if WithCanonicalJSON is set.
+func (c *Client) marshalBody(body interface{}) ([]byte, error) {
+	if c.canonicalJSON {
+		return CanonicalJSON(body)
+	}
+	return json.Marshal(body)
+}
@@ -0,0 +1,85 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSON_SortsKeysAtEveryLevel(t *testing.T) {
+	req := CreateInvoiceRequest{
+		InvoiceCode:     "INV",
+		SenderInvoiceNo: "ORD-1",
+		SenderTerminalData: map[string]interface{}{
+			"zeta":  1,
+			"alpha": map[string]interface{}{"y": "<b>", "x": 2.50},
+		},
+	}
+
+	got, err := CanonicalJSON(req)
+	if err != nil {
+		t.Fatalf("CanonicalJSON failed: %v", err)
+	}
+	if !json.Valid(got) {
+		t.Fatalf("invalid JSON: %s", got)
+	}
+
+	s := string(got)
+	want := `"sender_terminal_data":{"alpha":{"x":2.5,"y":"<b>"},"zeta":1}`
+	if !strings.Contains(s, want) {
+		t.Errorf("expected nested keys sorted as %s, got %s", want, s)
+	}
+	if !strings.HasPrefix(s, `{"amount":`) {
+		t.Errorf("expected top-level keys sorted, got %s", s)
+	}
+	if strings.Index(s, `"invoice_code"`) > strings.Index(s, `"sender_invoice_no"`) {
+		t.Errorf("expected invoice_code before sender_invoice_no, got %s", s)
+	}
+}
+
+func TestCanonicalJSON_Deterministic(t *testing.T) {
+	a := map[string]interface{}{"b": []interface{}{map[string]interface{}{"d": 1, "c": 2}}, "a": "x"}
+	b := map[string]interface{}{"a": "x", "b": []interface{}{map[string]interface{}{"c": 2, "d": 1}}}
+
+	ja, err := CanonicalJSON(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jb, err := CanonicalJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ja) != string(jb) {
+		t.Errorf("expected identical output, got %s and %s", ja, jb)
+	}
+	if string(ja) != `{"a":"x","b":[{"c":2,"d":1}]}` {
+		t.Errorf("unexpected canonical form %s", ja)
+	}
+}
+
+func TestWithCanonicalJSON_EncodesRequestBody(t *testing.T) {
+	var body string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+	WithCanonicalJSON()(client)
+
+	err := client.doRequest(context.Background(), testOp, "DELETE", "/v2/payment/cancel/p1", &PaymentCancelRequest{
+		Note:        "n",
+		CallbackURL: "https://example.com/cb",
+	}, nil)
+	if err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+
+	want := `{"callback_url":"https://example.com/cb","note":"n"}`
+	if body != want {
+		t.Errorf("expected body %s, got %s", want, body)
+	}
+}
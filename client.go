@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const tokenBufferSeconds = 30
@@ -19,43 +22,91 @@ type Client struct {
 	http   *http.Client
 	mu     sync.Mutex
 
-	accessToken      string
-	refreshToken     string
-	expiresAt        int64
-	refreshExpiresAt int64
+	// tokenState holds the token for the Config credentials; sessions holds
+	// tokens for credentials supplied per call via WithCredentials.
+	tokenState
+	sessions map[string]*tokenState
+
+	responseHooks []ResponseHook
+	tokenHooks    []TokenEventHook
+	canonicalJSON bool
+	invoices      *invoiceCache
+	checkAmounts  bool
+	defaultOffset *Offset
+	clock         func() time.Time
+	retry         RetryPolicy
+	rand          *lockedRand
+	limiter       *rate.Limiter
+	fees          FeeTable
+	lastErrors    *lastErrors
+
+	resolveInvoiceCode InvoiceCodeResolver
+	lookupInvoice      InvoiceLookup
+	suffixInvoiceNo    bool
+	rewriteURL         URLRewriter
+
+	// authContentType and authBody are the optional token request body set
+	// by WithAuthBody; a nil authBody sends none.
+	authContentType string
+	authBody        []byte
+
+	// initialToken is the WithInitialToken token, stored once all options
+	// have run.
+	initialToken *TokenResponse
 }
 
-// NewClient creates a new QPay client with the given configuration.
-func NewClient(cfg *Config) *Client {
-	return &Client{
-		config: cfg,
-		http: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// NewClient creates a new QPay client with the given configuration. Its
+// http.Client times out after cfg.RequestTimeout, or DefaultRequestTimeout
+// if that is zero.
+func NewClient(cfg *Config, opts ...Option) *Client {
+	timeout := cfg.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
 	}
+	return NewClientWithHTTPClient(cfg, &http.Client{
+		Timeout: timeout,
+	}, opts...)
 }
 
 // NewClientWithHTTPClient creates a new QPay client with a custom http.Client.
-func NewClientWithHTTPClient(cfg *Config, httpClient *http.Client) *Client {
-	return &Client{
+func NewClientWithHTTPClient(cfg *Config, httpClient *http.Client, opts ...Option) *Client {
+	c := &Client{
 		config: cfg,
 		http:   httpClient,
+		rand:   &lockedRand{r: rand.New(rand.NewSource(time.Now().UnixNano()))},
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.initialToken != nil {
+		c.tokenState.storeToken(c.initialToken, c.now())
+		c.initialToken = nil
+	}
+	return c
 }
 
 func (c *Client) ensureToken(ctx context.Context) error {
+	_, err := c.authorization(ctx)
+	return err
+}
+
+// authorization returns the Authorization header value for the credentials
+// carried by ctx, refreshing or re-authenticating as needed.
+func (c *Client) authorization(ctx context.Context) (string, error) {
 	c.mu.Lock()
-	now := time.Now().Unix()
+	state := c.session(ctx)
+	now := c.now().Unix()
 
 	// Access token still valid
-	if c.accessToken != "" && now < c.expiresAt-tokenBufferSeconds {
+	if state.accessToken != "" && now < state.expiresAt-tokenBufferSeconds {
+		header := state.authorization()
 		c.mu.Unlock()
-		return nil
+		return header, nil
 	}
 
 	// Determine strategy: refresh or full auth
-	canRefresh := c.refreshToken != "" && now < c.refreshExpiresAt-tokenBufferSeconds
-	refreshTok := c.refreshToken
+	canRefresh := state.refreshToken != "" && now < state.refreshExpiresAt-tokenBufferSeconds
+	refreshTok := state.refreshToken
 	c.mu.Unlock()
 
 	// Access token expired, try refresh
@@ -63,79 +114,121 @@ func (c *Client) ensureToken(ctx context.Context) error {
 		token, err := c.doRefreshTokenHTTP(ctx, refreshTok)
 		if err == nil {
 			c.mu.Lock()
-			c.storeToken(token)
+			state.storeToken(token, c.now())
+			header := state.authorization()
 			c.mu.Unlock()
-			return nil
+			return header, nil
 		}
 		// Refresh failed, fall through to get new token
+		c.tokenEvent(ctx, TokenEventFallback, err)
 	}
 
 	// Both expired or no tokens, get new token
 	token, err := c.getTokenRequest(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get token: %w", err)
+		return "", fmt.Errorf("failed to get token: %w", err)
 	}
 
 	c.mu.Lock()
-	c.storeToken(token)
+	state.storeToken(token, c.now())
+	header := state.authorization()
 	c.mu.Unlock()
-	return nil
+	return header, nil
 }
 
 // doRefreshTokenHTTP performs the HTTP call for token refresh without locking.
 func (c *Client) doRefreshTokenHTTP(ctx context.Context, refreshTok string) (*TokenResponse, error) {
-	url := c.config.BaseURL + "/v2/auth/refresh"
+	token, err := c.refreshTokenHTTP(ctx, refreshTok)
+	c.tokenEvent(ctx, TokenEventRefresh, err)
+	return token, err
+}
+
+func (c *Client) refreshTokenHTTP(ctx context.Context, refreshTok string) (*TokenResponse, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	url := c.config.BaseURL + c.apiPath("/auth/refresh")
 	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+refreshTok)
+	c.applyURLRewriter(req)
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+	resp, respBody, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		qErr := &Error{
-			StatusCode: resp.StatusCode,
-			RawBody:    string(respBody),
-		}
-		_ = json.Unmarshal(respBody, qErr)
+	if qErr := responseError(resp, respBody); qErr != nil {
+		c.recordError(OpAuthRefresh, qErr)
 		return nil, qErr
 	}
 
 	var token TokenResponse
 	if err := json.Unmarshal(respBody, &token); err != nil {
+		return nil, newDecodeError(resp.StatusCode, &token, respBody, err)
+	}
+	if err := token.validate(); err != nil {
 		return nil, err
 	}
 	return &token, nil
 }
 
-func (c *Client) storeToken(token *TokenResponse) {
-	c.accessToken = token.AccessToken
-	c.refreshToken = token.RefreshToken
-	c.expiresAt = token.ExpiresIn
-	c.refreshExpiresAt = token.RefreshExpiresIn
+// requestContext bounds ctx by Config.RequestTimeout, if set, so retries and
+// the token requests a call triggers share one deadline.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.config.RequestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.config.RequestTimeout)
 }
 
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	if err := c.ensureToken(ctx); err != nil {
-		return err
+// apiPath prefixes path with the configured API version segment.
+func (c *Client) apiPath(path string) string {
+	version := c.config.APIVersion
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+	return "/" + version + path
+}
+
+// ebarimtPath prefixes path with the configured API and ebarimt version segments.
+func (c *Client) ebarimtPath(path string) string {
+	version := c.config.EbarimtAPIVersion
+	if version == "" {
+		version = DefaultEbarimtAPIVersion
+	}
+	return c.apiPath("/" + version + path)
+}
+
+// doRequest sends an authorized request and decodes a JSON response into
+// result. A nil body sends no body and no Content-Type; any other body,
+// including an empty struct, is sent as JSON.
+func (c *Client) doRequest(ctx context.Context, op Operation, method, path string, body interface{}, result interface{}) error {
+	_, err := c.doRequestStatus(ctx, op, method, path, body, result)
+	return err
+}
+
+// doRequestStatus is doRequest that also returns the 2xx status code of a
+// successful response.
+func (c *Client) doRequestStatus(ctx context.Context, op Operation, method, path string, body interface{}, result interface{}) (int, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	authorization, err := c.authorization(ctx)
+	if err != nil {
+		return 0, err
 	}
 
+	// A *bytes.Reader body gets a GetBody from http.NewRequest, so each retry
+	// resends it from the start.
 	var bodyReader io.Reader
 	if body != nil {
-		data, err := json.Marshal(body)
+		data, err := c.marshalBody(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return 0, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(data)
 	}
@@ -143,85 +236,72 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	url := c.config.BaseURL + path
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	// Only advertise a JSON body when there is one; some WAFs reject a JSON
+	// content type on bodyless requests.
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer resp.Body.Close()
+	req.Header.Set("Authorization", authorization)
+	c.applyURLRewriter(req)
 
-	respBody, err := io.ReadAll(resp.Body)
+	resp, respBody, decoded, err := c.sendDecode(req, result)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return 0, err
+	}
+	if decoded {
+		return resp.StatusCode, nil
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		qErr := &Error{
-			StatusCode: resp.StatusCode,
-			RawBody:    string(respBody),
-		}
-		_ = json.Unmarshal(respBody, qErr)
-		if qErr.Code == "" {
-			qErr.Code = http.StatusText(resp.StatusCode)
-		}
-		if qErr.Message == "" {
-			qErr.Message = string(respBody)
-		}
-		return qErr
+	if qErr := responseError(resp, respBody); qErr != nil {
+		c.recordError(op, qErr)
+		return 0, qErr
 	}
 
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return 0, newDecodeError(resp.StatusCode, result, respBody, err)
 		}
 	}
 
-	return nil
+	return resp.StatusCode, nil
 }
 
 func (c *Client) doBasicAuthRequest(ctx context.Context, method, path string, result interface{}) error {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if c.authBody != nil {
+		bodyReader = bytes.NewReader(c.authBody)
+	}
+
 	url := c.config.BaseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.SetBasicAuth(c.config.Username, c.config.Password)
-
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	if bodyReader != nil && c.authContentType != "" {
+		req.Header.Set("Content-Type", c.authContentType)
 	}
-	defer resp.Body.Close()
+	req.Header.Set("Authorization", BasicAuthHeader(c.basicAuth(ctx)))
+	c.applyURLRewriter(req)
 
-	respBody, err := io.ReadAll(resp.Body)
+	resp, respBody, err := c.send(req)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		qErr := &Error{
-			StatusCode: resp.StatusCode,
-			RawBody:    string(respBody),
-		}
-		_ = json.Unmarshal(respBody, qErr)
-		if qErr.Code == "" {
-			qErr.Code = http.StatusText(resp.StatusCode)
-		}
-		if qErr.Message == "" {
-			qErr.Message = string(respBody)
-		}
+	if qErr := responseError(resp, respBody); qErr != nil {
 		return qErr
 	}
 
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return newDecodeError(resp.StatusCode, result, respBody, err)
 		}
 	}
 
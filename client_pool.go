@@ -0,0 +1,76 @@
+package qpay
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ClientPool holds one Client per named merchant profile, for operators with
+// several QPay accounts that each have their own Config. The clients share one
+// http.Client, and so its transport and connection pool, and are built with
+// the same options. Each client keeps its own tokens, and options that hold
+// state, such as WithInvoiceCache or WithRateLimit, give each profile its own.
+// A token belongs to one merchant, so WithInitialToken is ignored among the
+// shared options; pass it to Add for the profile it belongs to.
+//
+// For accounts that differ only in credentials, WithCredentials on a single
+// Client is lighter.
+type ClientPool struct {
+	http *http.Client
+	opts []Option
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewClientPool returns an empty pool whose clients use httpClient and opts.
+// A nil httpClient is replaced by one with DefaultRequestTimeout, as NewClient
+// would create.
+func NewClientPool(httpClient *http.Client, opts ...Option) *ClientPool {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultRequestTimeout}
+	}
+	return &ClientPool{http: httpClient, opts: opts, clients: make(map[string]*Client)}
+}
+
+// Add creates the client for profile name from cfg and returns it. Options in
+// opts apply to this profile only, after the pool's shared options. Adding a
+// name again replaces its client, discarding that profile's tokens.
+func (p *ClientPool) Add(name string, cfg *Config, opts ...Option) *Client {
+	all := make([]Option, 0, len(p.opts)+1+len(opts))
+	all = append(all, p.opts...)
+	all = append(all, withoutInitialToken)
+	all = append(all, opts...)
+	c := NewClientWithHTTPClient(cfg, p.http, all...)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[name] = c
+	return c
+}
+
+// For returns the client for profile name, or nil if no such profile was
+// added. It is safe for concurrent use.
+func (p *ClientPool) For(name string) *Client {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clients[name]
+}
+
+// Profiles returns the profile names in sorted order.
+func (p *ClientPool) Profiles() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withoutInitialToken drops a token seeded by the pool's shared options, so
+// one merchant's token never reaches another profile's client.
+func withoutInitialToken(c *Client) {
+	c.initialToken = nil
+}
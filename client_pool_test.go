@@ -0,0 +1,84 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientPool_SeparateTokensPerProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/auth/token" {
+			user, _, _ := r.BasicAuth()
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken: "token-" + user,
+				ExpiresIn:   time.Now().Unix() + 3600,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentDetail{PaymentID: r.Header.Get("Authorization")})
+	}))
+	defer server.Close()
+
+	var hooked int
+	pool := NewClientPool(server.Client(), WithResponseHook(func(*http.Request, *http.Response) { hooked++ }))
+	pool.Add("store-a", &Config{BaseURL: server.URL, Username: "a", Password: "p"})
+	pool.Add("store-b", &Config{BaseURL: server.URL, Username: "b", Password: "p"})
+
+	for name, want := range map[string]string{"store-a": "Bearer token-a", "store-b": "Bearer token-b"} {
+		payment, err := pool.For(name).GetPayment(context.Background(), "pay-1")
+		if err != nil {
+			t.Fatalf("%s: GetPayment failed: %v", name, err)
+		}
+		if payment.PaymentID != want {
+			t.Errorf("%s: expected %q, got %q", name, want, payment.PaymentID)
+		}
+	}
+	if hooked == 0 {
+		t.Error("expected shared options to apply to every profile")
+	}
+	if pool.For("store-a").http != pool.For("store-b").http {
+		t.Error("expected profiles to share the http.Client")
+	}
+}
+
+func TestClientPool_InitialTokenPerProfileOnly(t *testing.T) {
+	shared := WithInitialToken(&TokenResponse{AccessToken: "shared", ExpiresIn: 3600})
+	pool := NewClientPool(nil, shared)
+	a := pool.Add("store-a", &Config{BaseURL: "https://api.qpay.mn"})
+	b := pool.Add("store-b", &Config{BaseURL: "https://api.qpay.mn"},
+		WithInitialToken(&TokenResponse{AccessToken: "token-b", ExpiresIn: 3600}))
+
+	if got := a.AuthHeaderPreview(); got != "" {
+		t.Errorf("expected a shared WithInitialToken to be ignored, got %q", got)
+	}
+	if got := b.AuthHeaderPreview(); got == "" {
+		t.Error("expected the per-profile WithInitialToken to be stored")
+	}
+}
+
+func TestClientPool_ForAndProfiles(t *testing.T) {
+	pool := NewClientPool(nil)
+	if pool.For("missing") != nil {
+		t.Error("expected nil for an unknown profile")
+	}
+
+	first := pool.Add("b", &Config{BaseURL: "https://a.example"})
+	pool.Add("a", &Config{BaseURL: "https://a.example"})
+	if got := pool.For("b"); got != first {
+		t.Error("expected For to return the added client")
+	}
+	if got := pool.Add("b", &Config{BaseURL: "https://b.example"}); got == first || pool.For("b") != got {
+		t.Error("expected Add to replace the profile")
+	}
+	if got, want := pool.Profiles(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if pool.For("a").http.Timeout != DefaultRequestTimeout {
+		t.Errorf("expected default timeout, got %v", pool.For("a").http.Timeout)
+	}
+}
@@ -3,8 +3,10 @@ package qpay
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -41,6 +43,103 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_RequestTimeout(t *testing.T) {
+	client := NewClient(&Config{RequestTimeout: 3 * time.Second})
+	if client.http.Timeout != 3*time.Second {
+		t.Errorf("expected timeout 3s, got %v", client.http.Timeout)
+	}
+}
+
+func TestRequestTimeout_BoundsRetries(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+	client.config.RequestTimeout = 100 * time.Millisecond
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 10, BaseDelay: 40 * time.Millisecond, Jitter: JitterNone})(client)
+
+	start := time.Now()
+	if _, err := client.GetPayment(context.Background(), "pay-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("expected the call to stop near 100ms, took %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n >= 10 {
+		t.Errorf("expected retries to be cut short, got %d attempts", n)
+	}
+}
+
+func TestDoRequest_CanceledMidFlight(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+	defer server.Close()
+	defer close(release)
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.GetPayment(ctx, "pay-1")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a prompt return, took %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected no retry after cancel, got %d attempts", n)
+	}
+}
+
+// TestRequestContext_Canceled checks that the per-request contexts derived
+// for Config.RequestTimeout are canceled when each call returns, not left to
+// expire, for the API call and the token request it triggers.
+func TestRequestContext_Canceled(t *testing.T) {
+	for name, status := range map[string]int{"success": http.StatusOK, "error": http.StatusNotFound} {
+		t.Run(name, func(t *testing.T) {
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(PaymentDetail{PaymentID: "pay-1"})
+			})
+			defer server.Close()
+			client.config.RequestTimeout = time.Hour
+
+			var mu sync.Mutex
+			var ctxs []context.Context
+			WithRoundTripper(func(next http.RoundTripper) http.RoundTripper {
+				return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					mu.Lock()
+					ctxs = append(ctxs, req.Context())
+					mu.Unlock()
+					return next.RoundTrip(req)
+				})
+			})(client)
+
+			client.GetPayment(context.Background(), "pay-1")
+
+			if len(ctxs) != 2 {
+				t.Fatalf("expected token and payment requests, got %d", len(ctxs))
+			}
+			for i, ctx := range ctxs {
+				if !errors.Is(ctx.Err(), context.Canceled) {
+					t.Errorf("request %d: expected its context to be canceled, got %v", i, ctx.Err())
+				}
+			}
+		})
+	}
+}
+
 func TestNewClientWithHTTPClient(t *testing.T) {
 	cfg := &Config{
 		BaseURL:  "https://api.qpay.mn",
@@ -266,9 +365,10 @@ func TestDoRequest_Success(t *testing.T) {
 			if auth != "Bearer test-token" {
 				t.Errorf("expected Authorization 'Bearer test-token', got %q", auth)
 			}
+			// A bodyless request must not advertise a JSON body
 			ct := r.Header.Get("Content-Type")
-			if ct != "application/json" {
-				t.Errorf("expected Content-Type 'application/json', got %q", ct)
+			if ct != "" {
+				t.Errorf("expected no Content-Type, got %q", ct)
 			}
 			json.NewEncoder(w).Encode(map[string]string{"result": "ok"})
 			return
@@ -283,7 +383,7 @@ func TestDoRequest_Success(t *testing.T) {
 	}, server.Client())
 
 	var result map[string]string
-	err := client.doRequest(context.Background(), "GET", "/v2/test", nil, &result)
+	err := client.doRequest(context.Background(), testOp, "GET", "/v2/test", nil, &result)
 	if err != nil {
 		t.Fatalf("doRequest failed: %v", err)
 	}
@@ -318,7 +418,7 @@ func TestDoRequest_APIError(t *testing.T) {
 	}, server.Client())
 
 	var result map[string]string
-	err := client.doRequest(context.Background(), "GET", "/v2/test", nil, &result)
+	err := client.doRequest(context.Background(), testOp, "GET", "/v2/test", nil, &result)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -358,7 +458,7 @@ func TestDoRequest_ServerError500(t *testing.T) {
 	}, server.Client())
 
 	var result map[string]string
-	err := client.doRequest(context.Background(), "GET", "/v2/test", nil, &result)
+	err := client.doRequest(context.Background(), testOp, "GET", "/v2/test", nil, &result)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -394,7 +494,7 @@ func TestDoRequest_NilResult(t *testing.T) {
 	}, server.Client())
 
 	// Passing nil result should not panic
-	err := client.doRequest(context.Background(), "DELETE", "/v2/test", nil, nil)
+	err := client.doRequest(context.Background(), testOp, "DELETE", "/v2/test", nil, nil)
 	if err != nil {
 		t.Fatalf("doRequest with nil result failed: %v", err)
 	}
@@ -433,7 +533,7 @@ func TestDoRequest_WithBody(t *testing.T) {
 
 	reqBody := map[string]string{"key": "value"}
 	var result map[string]string
-	err := client.doRequest(context.Background(), "POST", "/v2/test", reqBody, &result)
+	err := client.doRequest(context.Background(), testOp, "POST", "/v2/test", reqBody, &result)
 	if err != nil {
 		t.Fatalf("doRequest with body failed: %v", err)
 	}
@@ -512,8 +612,11 @@ func TestDoBasicAuthRequest_Error(t *testing.T) {
 	}
 }
 
+// testOp is the Operation tests pass when calling doRequest directly.
+const testOp Operation = "test"
+
 // testHelper creates a mock server with token auth and a custom handler for the API path.
-func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+func newTestClient(t testing.TB, handler http.HandlerFunc) (*Client, *httptest.Server) {
 	t.Helper()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/v2/auth/token" {
@@ -538,3 +641,136 @@ func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.S
 
 	return client, server
 }
+
+func TestAPIPath_Defaults(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn"})
+
+	if got := client.apiPath("/invoice"); got != "/v2/invoice" {
+		t.Errorf("expected '/v2/invoice', got %q", got)
+	}
+	if got := client.ebarimtPath("/create"); got != "/v2/ebarimt_v3/create" {
+		t.Errorf("expected '/v2/ebarimt_v3/create', got %q", got)
+	}
+}
+
+func TestAPIPath_CustomVersions(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/v3/auth/token" {
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken:      "test-token",
+				RefreshToken:     "test-refresh",
+				ExpiresIn:        time.Now().Unix() + 3600,
+				RefreshExpiresIn: time.Now().Unix() + 7200,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(EbarimtResponse{ID: "ebarimt-001"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:           server.URL,
+		Username:          "user",
+		Password:          "pass",
+		APIVersion:        "v3",
+		EbarimtAPIVersion: "ebarimt_v4",
+	}, server.Client())
+
+	if _, err := client.CreateEbarimt(context.Background(), &CreateEbarimtRequest{PaymentID: "pay-1"}); err != nil {
+		t.Fatalf("CreateEbarimt failed: %v", err)
+	}
+
+	expected := []string{"/v3/auth/token", "/v3/ebarimt_v4/create"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected paths %v, got %v", expected, paths)
+	}
+	for i, p := range expected {
+		if paths[i] != p {
+			t.Errorf("expected path %q, got %q", p, paths[i])
+		}
+	}
+}
+
+func TestDoRequest_AuthorizationUsesTokenType(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokenType string
+		expected  string
+	}{
+		{"bearer", "Bearer", "Bearer tok"},
+		{"empty defaults to bearer", "", "Bearer tok"},
+		{"other type", "MAC", "MAC tok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v2/auth/token" {
+					json.NewEncoder(w).Encode(TokenResponse{
+						TokenType:        tt.tokenType,
+						AccessToken:      "tok",
+						ExpiresIn:        time.Now().Unix() + 3600,
+						RefreshExpiresIn: time.Now().Unix() + 7200,
+					})
+					return
+				}
+				got = r.Header.Get("Authorization")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+			if err := client.doRequest(context.Background(), testOp, "GET", "/v2/payment/p1", nil, nil); err != nil {
+				t.Fatalf("doRequest failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected Authorization %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDoRequest_ContentTypeOnlyWithBody(t *testing.T) {
+	var contentTypes []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	if err := client.doRequest(context.Background(), testOp, "DELETE", "/v2/test", nil, nil); err != nil {
+		t.Fatalf("doRequest without body failed: %v", err)
+	}
+	if err := client.doRequest(context.Background(), testOp, "POST", "/v2/test", map[string]string{"key": "value"}, nil); err != nil {
+		t.Fatalf("doRequest with body failed: %v", err)
+	}
+
+	if contentTypes[0] != "" {
+		t.Errorf("expected no Content-Type without a body, got %q", contentTypes[0])
+	}
+	if contentTypes[1] != "application/json" {
+		t.Errorf("expected application/json with a body, got %q", contentTypes[1])
+	}
+}
+
+// BenchmarkDoRequest_Body measures a JSON-bodied call end to end; run with
+// -benchmem to see allocs/op.
+func BenchmarkDoRequest_Body(b *testing.B) {
+	client, server := newTestClient(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	req := lookupRequest()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.doRequest(ctx, testOp, "POST", "/v2/invoice", req, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package qpay
+
+import (
+	"context"
+	"time"
+)
+
+// ObjectTypeInvoice is the PaymentCheckRequest.ObjectType for invoices.
+const ObjectTypeInvoice = "INVOICE"
+
+// DefaultPollInterval is how often WaitForPayment checks when
+// WaitOptions.Interval is zero.
+const DefaultPollInterval = 3 * time.Second
+
+// WaitOptions configures WaitForPayment, AwaitPayment and WaitForSettlement.
+type WaitOptions struct {
+	// Interval between checks; DefaultPollInterval if zero.
+	Interval time.Duration
+	// OnStatusChange, if set, is called with the invoice's payment status
+	// whenever it differs from the previous check, including the first check.
+	// The status is PaymentStatusPaid once paid, otherwise the status of the
+	// first row, or "" while there are no payments.
+	OnStatusChange func(status string)
+}
+
+// WaitForPayment polls CheckPayment for an invoice until it has a PAID
+// payment and returns that row, chosen as in GetLatestPayment. It gives up
+// when ctx is done or a check fails; set ctx's deadline to bound the wait.
+// opts may be nil.
+func (c *Client) WaitForPayment(ctx context.Context, invoiceID string, opts *WaitOptions) (*PaymentCheckRow, error) {
+	return c.AwaitPayment(ctx, ObjectTypeInvoice, invoiceID, nil, opts)
+}
+
+// AwaitPayment is WaitForPayment for any object type, also woken by
+// callbackSignal. Close or send on callbackSignal when the QPay callback for
+// the object arrives: AwaitPayment checks at once instead of waiting out the
+// interval. The callback itself is not trusted; the payment is only returned
+// once CheckPayment reports it PAID, and polling carries on if it doesn't yet.
+// A nil callbackSignal polls only.
+func (c *Client) AwaitPayment(ctx context.Context, objectType, objectID string, callbackSignal <-chan struct{}, opts *WaitOptions) (*PaymentCheckRow, error) {
+	var o WaitOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultPollInterval
+	}
+
+	var last string
+	for first := true; ; first = false {
+		resp, err := c.CheckPayment(ctx, &PaymentCheckRequest{
+			ObjectType: objectType,
+			ObjectID:   objectID,
+			Offset:     &Offset{},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		paid := latestPaidRow(resp.Rows)
+		status := ""
+		switch {
+		case paid != nil:
+			status = PaymentStatusPaid
+		case len(resp.Rows) > 0:
+			status = resp.Rows[0].PaymentStatus
+		}
+		if o.OnStatusChange != nil && (first || status != last) {
+			o.OnStatusChange(status)
+		}
+		last = status
+
+		if paid != nil {
+			return paid, nil
+		}
+
+		t := time.NewTimer(o.Interval)
+		select {
+		case <-t.C:
+		case _, ok := <-callbackSignal:
+			t.Stop()
+			if !ok {
+				// A closed channel is always ready; poll on the timer from now on.
+				callbackSignal = nil
+			}
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// CollectOptions configures CollectPayment. Each step can be replaced; the
+// defaults use the fields documented on them.
+type CollectOptions struct {
+	// Invoice is created by the default CreateInvoice step.
+	Invoice *CreateInvoiceRequest
+	// Wait configures the default WaitForPayment step.
+	Wait WaitOptions
+	// Ebarimt, if set, is created by the default CreateEbarimt step once the
+	// invoice is paid. An empty PaymentID is filled from the paid row.
+	Ebarimt *CreateEbarimtRequest
+
+	// OnInvoiceCreated is called after the invoice is created, e.g. to show
+	// its QR code and deeplinks to the customer.
+	OnInvoiceCreated func(invoice *InvoiceResponse)
+
+	// CreateInvoice replaces the invoice creation step.
+	CreateInvoice func(ctx context.Context) (*InvoiceResponse, error)
+	// WaitForPayment replaces the polling step.
+	WaitForPayment func(ctx context.Context, invoice *InvoiceResponse) (*PaymentCheckRow, error)
+	// CreateEbarimt replaces the receipt step. It runs whenever it or Ebarimt is set.
+	CreateEbarimt func(ctx context.Context, payment *PaymentCheckRow) (*EbarimtResponse, error)
+}
+
+// CollectResult holds what CollectPayment produced.
+type CollectResult struct {
+	Invoice *InvoiceResponse
+	Payment *PaymentCheckRow
+	Ebarimt *EbarimtResponse
+}
+
+// CollectPayment runs the usual checkout flow: create an invoice, wait until
+// it is paid, then optionally create an ebarimt. When a later step fails, the
+// returned result still holds what earlier steps produced, so the caller can
+// e.g. cancel an invoice that was never paid.
+func (c *Client) CollectPayment(ctx context.Context, opts CollectOptions) (*CollectResult, error) {
+	create := opts.CreateInvoice
+	if create == nil {
+		create = func(ctx context.Context) (*InvoiceResponse, error) {
+			if opts.Invoice == nil {
+				return nil, &ValidationError{Field: "invoice", Message: "required unless CreateInvoice is set"}
+			}
+			return c.CreateInvoice(ctx, opts.Invoice)
+		}
+	}
+	wait := opts.WaitForPayment
+	if wait == nil {
+		wait = func(ctx context.Context, invoice *InvoiceResponse) (*PaymentCheckRow, error) {
+			return c.WaitForPayment(ctx, invoice.InvoiceID, &opts.Wait)
+		}
+	}
+	receipt := opts.CreateEbarimt
+	if receipt == nil && opts.Ebarimt != nil {
+		receipt = func(ctx context.Context, payment *PaymentCheckRow) (*EbarimtResponse, error) {
+			req := *opts.Ebarimt
+			if req.PaymentID == "" {
+				req.PaymentID = payment.PaymentID
+			}
+			return c.CreateEbarimt(ctx, &req)
+		}
+	}
+
+	result := &CollectResult{}
+	invoice, err := create(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Invoice = invoice
+	if opts.OnInvoiceCreated != nil {
+		opts.OnInvoiceCreated(invoice)
+	}
+
+	payment, err := wait(ctx, invoice)
+	if err != nil {
+		return result, err
+	}
+	result.Payment = payment
+
+	if receipt != nil {
+		ebarimt, err := receipt(ctx, payment)
+		if err != nil {
+			return result, err
+		}
+		result.Ebarimt = ebarimt
+	}
+	return result, nil
+}
@@ -0,0 +1,187 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCollectServer serves invoice creation, a payment check that reports
+// paid after paidAfter checks, and ebarimt creation.
+func newCollectServer(t *testing.T, paidAfter int32, checks *int32, ebarimtReq *CreateEbarimtRequest) (*Client, func()) {
+	t.Helper()
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/invoice":
+			json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1", QRText: "qr"})
+		case "/v2/payment/check":
+			n := atomic.AddInt32(checks, 1)
+			resp := PaymentCheckResponse{}
+			if n > 1 {
+				resp.Rows = []PaymentCheckRow{{PaymentID: "pay-1", PaymentStatus: PaymentStatusNew}}
+			}
+			if n >= paidAfter {
+				resp.Rows[0].PaymentStatus = PaymentStatusPaid
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/v2/ebarimt_v3/create":
+			json.NewDecoder(r.Body).Decode(ebarimtReq)
+			json.NewEncoder(w).Encode(EbarimtResponse{ID: "eb-1"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	return client, server.Close
+}
+
+func TestWaitForPayment(t *testing.T) {
+	var checks int32
+	client, done := newCollectServer(t, 3, &checks, nil)
+	defer done()
+
+	var statuses []string
+	row, err := client.WaitForPayment(context.Background(), "inv-1", &WaitOptions{
+		Interval:       time.Millisecond,
+		OnStatusChange: func(status string) { statuses = append(statuses, status) },
+	})
+	if err != nil {
+		t.Fatalf("WaitForPayment failed: %v", err)
+	}
+	if row.PaymentID != "pay-1" {
+		t.Errorf("expected pay-1, got %q", row.PaymentID)
+	}
+	if checks != 3 {
+		t.Errorf("expected 3 checks, got %d", checks)
+	}
+	want := []string{"", PaymentStatusNew, PaymentStatusPaid}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected statuses %v, got %v", want, statuses)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("status %d: expected %q, got %q", i, want[i], statuses[i])
+		}
+	}
+}
+
+func TestWaitForPayment_ContextDeadline(t *testing.T) {
+	var checks int32
+	client, done := newCollectServer(t, 1000, &checks, nil)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForPayment(ctx, "inv-1", &WaitOptions{Interval: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestAwaitPayment_CallbackSkipsInterval(t *testing.T) {
+	var checks int32
+	client, done := newCollectServer(t, 3, &checks, nil)
+	defer done()
+
+	signal := make(chan struct{}, 2)
+	signal <- struct{}{}
+	signal <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	row, err := client.AwaitPayment(ctx, ObjectTypeInvoice, "inv-1", signal, &WaitOptions{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("AwaitPayment failed: %v", err)
+	}
+	if row.PaymentStatus != PaymentStatusPaid || checks != 3 {
+		t.Errorf("expected paid after 3 checks, got %q after %d", row.PaymentStatus, checks)
+	}
+}
+
+func TestAwaitPayment_ClosedSignalKeepsPolling(t *testing.T) {
+	var checks int32
+	client, done := newCollectServer(t, 4, &checks, nil)
+	defer done()
+
+	// The callback arrived before QPay reported the payment; polling must
+	// confirm it rather than trust the signal.
+	signal := make(chan struct{})
+	close(signal)
+
+	row, err := client.AwaitPayment(context.Background(), ObjectTypeInvoice, "inv-1", signal, &WaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("AwaitPayment failed: %v", err)
+	}
+	if row.PaymentStatus != PaymentStatusPaid || checks != 4 {
+		t.Errorf("expected paid after 4 checks, got %q after %d", row.PaymentStatus, checks)
+	}
+}
+
+func TestCollectPayment(t *testing.T) {
+	var checks int32
+	var ebarimtReq CreateEbarimtRequest
+	client, done := newCollectServer(t, 2, &checks, &ebarimtReq)
+	defer done()
+
+	var created *InvoiceResponse
+	result, err := client.CollectPayment(context.Background(), CollectOptions{
+		Invoice: &CreateInvoiceRequest{SenderInvoiceNo: "ORD-1", Amount: 1000},
+		Wait:    WaitOptions{Interval: time.Millisecond},
+		Ebarimt: &CreateEbarimtRequest{EbarimtReceiverType: EbarimtReceiverCitizen},
+		OnInvoiceCreated: func(invoice *InvoiceResponse) {
+			created = invoice
+		},
+	})
+	if err != nil {
+		t.Fatalf("CollectPayment failed: %v", err)
+	}
+	if created == nil || created.InvoiceID != "inv-1" {
+		t.Errorf("expected OnInvoiceCreated with inv-1, got %v", created)
+	}
+	if result.Invoice.InvoiceID != "inv-1" || result.Payment.PaymentID != "pay-1" || result.Ebarimt.ID != "eb-1" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if ebarimtReq.PaymentID != "pay-1" {
+		t.Errorf("expected ebarimt for pay-1, got %q", ebarimtReq.PaymentID)
+	}
+}
+
+func TestCollectPayment_OverriddenSteps(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "http://unused.invalid"})
+	waitErr := errors.New("customer walked away")
+
+	result, err := client.CollectPayment(context.Background(), CollectOptions{
+		CreateInvoice: func(ctx context.Context) (*InvoiceResponse, error) {
+			return &InvoiceResponse{InvoiceID: "inv-custom"}, nil
+		},
+		WaitForPayment: func(ctx context.Context, invoice *InvoiceResponse) (*PaymentCheckRow, error) {
+			if invoice.InvoiceID != "inv-custom" {
+				t.Errorf("expected custom invoice, got %q", invoice.InvoiceID)
+			}
+			return nil, waitErr
+		},
+		CreateEbarimt: func(ctx context.Context, payment *PaymentCheckRow) (*EbarimtResponse, error) {
+			t.Error("ebarimt step should not run after a failed wait")
+			return nil, nil
+		},
+	})
+	if !errors.Is(err, waitErr) {
+		t.Fatalf("expected wait error, got %v", err)
+	}
+	if result.Invoice == nil || result.Invoice.InvoiceID != "inv-custom" || result.Payment != nil {
+		t.Errorf("expected partial result with the invoice, got %+v", result)
+	}
+}
+
+func TestCollectPayment_MissingInvoice(t *testing.T) {
+	client := NewClient(&Config{})
+	_, err := client.CollectPayment(context.Background(), CollectOptions{})
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "invoice" {
+		t.Errorf("expected invoice ValidationError, got %v", err)
+	}
+}
@@ -1,19 +1,47 @@
 package qpay
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"time"
+)
+
+// Default API path segments, used when the corresponding Config field is empty.
+const (
+	DefaultAPIVersion        = "v2"
+	DefaultEbarimtAPIVersion = "ebarimt_v3"
 )
 
 // Config holds the QPay API configuration.
 type Config struct {
-	BaseURL     string
-	Username    string
-	Password    string
-	InvoiceCode string
-	CallbackURL string
+	BaseURL     string `json:"base_url"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	InvoiceCode string `json:"invoice_code"`
+	CallbackURL string `json:"callback_url"`
+
+	// APIVersion is the version segment prefixing every endpoint path
+	// (e.g. "v2" in /v2/invoice). Defaults to DefaultAPIVersion.
+	APIVersion string `json:"api_version,omitempty"`
+	// EbarimtAPIVersion is the ebarimt segment nested under APIVersion
+	// (e.g. "ebarimt_v3" in /v2/ebarimt_v3/create). Defaults to DefaultEbarimtAPIVersion.
+	EbarimtAPIVersion string `json:"ebarimt_api_version,omitempty"`
+
+	// RequestTimeout bounds each client call, including retries and any
+	// token request it triggers. NewClient also uses it as the http.Client
+	// Timeout. Zero means DefaultRequestTimeout for NewClient and no extra
+	// bound for NewClientWithHTTPClient. Config files and
+	// QPAY_REQUEST_TIMEOUT give it as a time.ParseDuration string, e.g. "30s".
+	RequestTimeout time.Duration `json:"-"`
 }
 
+// DefaultRequestTimeout is the http.Client Timeout NewClient uses when
+// Config.RequestTimeout is zero.
+const DefaultRequestTimeout = 30 * time.Second
+
 // LoadConfigFromEnv loads QPay configuration from environment variables.
 //
 // Required environment variables:
@@ -22,13 +50,16 @@ type Config struct {
 //   - QPAY_PASSWORD: QPay merchant password
 //   - QPAY_INVOICE_CODE: Default invoice code
 //   - QPAY_CALLBACK_URL: Payment callback URL
+//
+// Optional environment variables:
+//   - QPAY_API_VERSION: API version path segment (default "v2")
+//   - QPAY_EBARIMT_API_VERSION: Ebarimt version path segment (default "ebarimt_v3")
+//   - QPAY_REQUEST_TIMEOUT: Config.RequestTimeout in time.ParseDuration
+//     format, e.g. "3s" (default 30s)
 func LoadConfigFromEnv() (*Config, error) {
-	cfg := &Config{
-		BaseURL:     os.Getenv("QPAY_BASE_URL"),
-		Username:    os.Getenv("QPAY_USERNAME"),
-		Password:    os.Getenv("QPAY_PASSWORD"),
-		InvoiceCode: os.Getenv("QPAY_INVOICE_CODE"),
-		CallbackURL: os.Getenv("QPAY_CALLBACK_URL"),
+	cfg, err := readEnvConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	required := map[string]string{
@@ -47,3 +78,201 @@ func LoadConfigFromEnv() (*Config, error) {
 
 	return cfg, nil
 }
+
+// readEnvConfig reads the QPay environment variables without checking required
+// fields. Only a malformed QPAY_REQUEST_TIMEOUT is an error.
+func readEnvConfig() (*Config, error) {
+	cfg := &Config{
+		BaseURL:     os.Getenv("QPAY_BASE_URL"),
+		Username:    os.Getenv("QPAY_USERNAME"),
+		Password:    os.Getenv("QPAY_PASSWORD"),
+		InvoiceCode: os.Getenv("QPAY_INVOICE_CODE"),
+		CallbackURL: os.Getenv("QPAY_CALLBACK_URL"),
+
+		APIVersion:        os.Getenv("QPAY_API_VERSION"),
+		EbarimtAPIVersion: os.Getenv("QPAY_EBARIMT_API_VERSION"),
+	}
+
+	if s := os.Getenv("QPAY_REQUEST_TIMEOUT"); s != "" {
+		d, err := parseRequestTimeout(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QPAY_REQUEST_TIMEOUT: %w", err)
+		}
+		cfg.RequestTimeout = d
+	}
+	return cfg, nil
+}
+
+// envRef matches ${NAME} references interpolated by LoadConfigFromFile.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadConfigFromFile loads QPay configuration from a JSON file. Field names
+// match Config's JSON tags:
+//
+//	{
+//	  "base_url": "https://merchant.qpay.mn",
+//	  "username": "your_username",
+//	  "password": "${QPAY_PASSWORD}",
+//	  "invoice_code": "YOUR_INVOICE_CODE",
+//	  "callback_url": "https://yoursite.com/qpay/callback",
+//	  "request_timeout": "30s"
+//	}
+//
+// request_timeout is optional and sets Config.RequestTimeout in
+// time.ParseDuration format. ${NAME} references in values are replaced with
+// the environment variable NAME, so secrets can stay out of the file. Unknown
+// fields are rejected, and the same fields LoadConfigFromEnv requires must be
+// non-empty.
+func LoadConfigFromFile(path string) (*Config, error) {
+	cfg, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := cfg.missingField(); name != "" {
+		return nil, fmt.Errorf("required config field %s is not set in %s", name, path)
+	}
+
+	return cfg, nil
+}
+
+// readConfigFile decodes a JSON config file and interpolates environment
+// references without checking required fields.
+func readConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file configFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	cfg := file.Config
+	for _, field := range cfg.fields() {
+		*field = expandEnvRefs(*field)
+	}
+	if s := expandEnvRefs(file.RequestTimeout); s != "" {
+		d, err := parseRequestTimeout(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request_timeout in %s: %w", path, err)
+		}
+		cfg.RequestTimeout = d
+	}
+
+	return &cfg, nil
+}
+
+// configFile is the JSON layout of a config file: Config's fields plus
+// request_timeout as a duration string.
+type configFile struct {
+	Config
+	RequestTimeout string `json:"request_timeout,omitempty"`
+}
+
+// parseRequestTimeout parses a non-negative time.ParseDuration string.
+func parseRequestTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q must be a non-negative duration such as \"3s\"", s)
+	}
+	return d, nil
+}
+
+// expandEnvRefs replaces ${NAME} references in s with environment values.
+// Bare $NAME is left alone so values like passwords may contain '$'.
+func expandEnvRefs(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		return os.Getenv(envRef.FindStringSubmatch(ref)[1])
+	})
+}
+
+// ConfigSource supplies a partial Config to LoadConfig. Empty fields are left
+// for other sources to fill.
+type ConfigSource func() (*Config, error)
+
+// ConfigFromFile reads a JSON config file as described on LoadConfigFromFile.
+func ConfigFromFile(path string) ConfigSource {
+	return func() (*Config, error) {
+		return readConfigFile(path)
+	}
+}
+
+// ConfigFromEnv reads the environment variables described on LoadConfigFromEnv.
+func ConfigFromEnv() ConfigSource {
+	return func() (*Config, error) {
+		return readEnvConfig()
+	}
+}
+
+// ConfigFromValues supplies explicit values, such as defaults or overrides.
+func ConfigFromValues(cfg Config) ConfigSource {
+	return func() (*Config, error) {
+		return &cfg, nil
+	}
+}
+
+// LoadConfig merges sources in the order given and validates the result.
+// Each non-empty field of a later source overrides the same field of earlier
+// ones, so sources should be listed from lowest to highest precedence:
+//
+//	cfg, err := qpay.LoadConfig(
+//		qpay.ConfigFromValues(defaults), // lowest
+//		qpay.ConfigFromFile("qpay.json"),
+//		qpay.ConfigFromEnv(),
+//		qpay.ConfigFromValues(overrides), // highest
+//	)
+//
+// The merged Config must set the same fields LoadConfigFromEnv requires.
+func LoadConfig(sources ...ConfigSource) (*Config, error) {
+	cfg := &Config{}
+	for _, source := range sources {
+		src, err := source()
+		if err != nil {
+			return nil, err
+		}
+		dst := cfg.fields()
+		for i, val := range src.fields() {
+			if *val != "" {
+				*dst[i] = *val
+			}
+		}
+		if src.RequestTimeout != 0 {
+			cfg.RequestTimeout = src.RequestTimeout
+		}
+	}
+
+	if name := cfg.missingField(); name != "" {
+		return nil, fmt.Errorf("required config field %s is not set", name)
+	}
+
+	return cfg, nil
+}
+
+// fields returns pointers to every string Config field, in declaration order.
+func (c *Config) fields() []*string {
+	return []*string{
+		&c.BaseURL, &c.Username, &c.Password, &c.InvoiceCode, &c.CallbackURL,
+		&c.APIVersion, &c.EbarimtAPIVersion,
+	}
+}
+
+// missingField returns the JSON name of the first empty required field, or "".
+func (c *Config) missingField() string {
+	required := []struct{ name, val string }{
+		{"base_url", c.BaseURL},
+		{"username", c.Username},
+		{"password", c.Password},
+		{"invoice_code", c.InvoiceCode},
+		{"callback_url", c.CallbackURL},
+	}
+	for _, f := range required {
+		if f.val == "" {
+			return f.name
+		}
+	}
+	return ""
+}
@@ -1,9 +1,13 @@
 package qpay
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigFromEnv_Success(t *testing.T) {
@@ -214,3 +218,262 @@ func TestLoadConfigFromEnv_EmptyValue(t *testing.T) {
 		t.Errorf("error should mention QPAY_BASE_URL, got: %v", err)
 	}
 }
+
+func TestLoadConfigFromEnv_APIVersions(t *testing.T) {
+	envVars := map[string]string{
+		"QPAY_BASE_URL":            "https://merchant.qpay.mn",
+		"QPAY_USERNAME":            "testuser",
+		"QPAY_PASSWORD":            "testpass",
+		"QPAY_INVOICE_CODE":        "INV_CODE",
+		"QPAY_CALLBACK_URL":        "https://example.com/callback",
+		"QPAY_API_VERSION":         "v3",
+		"QPAY_EBARIMT_API_VERSION": "ebarimt_v4",
+	}
+	for k, v := range envVars {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range envVars {
+			os.Unsetenv(k)
+		}
+	}()
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+	if cfg.APIVersion != "v3" {
+		t.Errorf("expected APIVersion 'v3', got %q", cfg.APIVersion)
+	}
+	if cfg.EbarimtAPIVersion != "ebarimt_v4" {
+		t.Errorf("expected EbarimtAPIVersion 'ebarimt_v4', got %q", cfg.EbarimtAPIVersion)
+	}
+}
+
+func TestLoadConfigFromEnv_RequestTimeout(t *testing.T) {
+	envVars := map[string]string{
+		"QPAY_BASE_URL":        "https://merchant.qpay.mn",
+		"QPAY_USERNAME":        "testuser",
+		"QPAY_PASSWORD":        "testpass",
+		"QPAY_INVOICE_CODE":    "INV_CODE",
+		"QPAY_CALLBACK_URL":    "https://example.com/callback",
+		"QPAY_REQUEST_TIMEOUT": "3s",
+	}
+	for k, v := range envVars {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range envVars {
+			os.Unsetenv(k)
+		}
+	}()
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+	if cfg.RequestTimeout != 3*time.Second {
+		t.Errorf("expected RequestTimeout 3s, got %v", cfg.RequestTimeout)
+	}
+
+	for _, bad := range []string{"3", "soon", "-1s"} {
+		os.Setenv("QPAY_REQUEST_TIMEOUT", bad)
+		if _, err := LoadConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "QPAY_REQUEST_TIMEOUT") {
+			t.Errorf("%q: expected QPAY_REQUEST_TIMEOUT error, got %v", bad, err)
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "qpay.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFromFile_Success(t *testing.T) {
+	os.Setenv("QPAY_TEST_FILE_PASSWORD", "secret$pass")
+	defer os.Unsetenv("QPAY_TEST_FILE_PASSWORD")
+
+	path := writeConfigFile(t, `{
+		"base_url": "https://merchant.qpay.mn",
+		"username": "testuser",
+		"password": "${QPAY_TEST_FILE_PASSWORD}",
+		"invoice_code": "INV_$CODE",
+		"callback_url": "https://example.com/callback",
+		"api_version": "v2"
+	}`)
+
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFromFile failed: %v", err)
+	}
+
+	if cfg.BaseURL != "https://merchant.qpay.mn" {
+		t.Errorf("expected BaseURL 'https://merchant.qpay.mn', got %q", cfg.BaseURL)
+	}
+	if cfg.Username != "testuser" {
+		t.Errorf("expected Username 'testuser', got %q", cfg.Username)
+	}
+	if cfg.Password != "secret$pass" {
+		t.Errorf("expected interpolated Password 'secret$pass', got %q", cfg.Password)
+	}
+	if cfg.InvoiceCode != "INV_$CODE" {
+		t.Errorf("bare $ should not be expanded, got %q", cfg.InvoiceCode)
+	}
+	if cfg.APIVersion != "v2" {
+		t.Errorf("expected APIVersion 'v2', got %q", cfg.APIVersion)
+	}
+}
+
+func TestLoadConfigFromFile_MissingField(t *testing.T) {
+	os.Unsetenv("QPAY_TEST_UNSET_PASSWORD")
+	path := writeConfigFile(t, `{
+		"base_url": "https://merchant.qpay.mn",
+		"username": "testuser",
+		"password": "${QPAY_TEST_UNSET_PASSWORD}",
+		"invoice_code": "INV_CODE",
+		"callback_url": "https://example.com/callback"
+	}`)
+
+	_, err := LoadConfigFromFile(path)
+	if err == nil {
+		t.Fatal("expected error for empty password, got nil")
+	}
+	if !strings.Contains(err.Error(), "password") {
+		t.Errorf("error should mention password, got: %v", err)
+	}
+}
+
+func TestLoadConfigFromFile_RequestTimeout(t *testing.T) {
+	os.Setenv("QPAY_TEST_TIMEOUT", "45s")
+	defer os.Unsetenv("QPAY_TEST_TIMEOUT")
+	body := `{
+		"base_url": "https://merchant.qpay.mn",
+		"username": "testuser",
+		"password": "secret",
+		"invoice_code": "INV_CODE",
+		"callback_url": "https://example.com/callback",
+		"request_timeout": %q
+	}`
+
+	for value, want := range map[string]time.Duration{"5s": 5 * time.Second, "${QPAY_TEST_TIMEOUT}": 45 * time.Second} {
+		cfg, err := LoadConfigFromFile(writeConfigFile(t, fmt.Sprintf(body, value)))
+		if err != nil {
+			t.Fatalf("%s: LoadConfigFromFile failed: %v", value, err)
+		}
+		if cfg.RequestTimeout != want {
+			t.Errorf("%s: expected %v, got %v", value, want, cfg.RequestTimeout)
+		}
+	}
+
+	_, err := LoadConfigFromFile(writeConfigFile(t, fmt.Sprintf(body, "soon")))
+	if err == nil || !strings.Contains(err.Error(), "request_timeout") {
+		t.Errorf("expected a request_timeout error, got %v", err)
+	}
+}
+
+func TestLoadConfigFromFile_UnknownField(t *testing.T) {
+	path := writeConfigFile(t, `{"base_url": "https://merchant.qpay.mn", "usrname": "typo"}`)
+
+	_, err := LoadConfigFromFile(path)
+	if err == nil {
+		t.Fatal("expected error for unknown field, got nil")
+	}
+	if !strings.Contains(err.Error(), "usrname") {
+		t.Errorf("error should mention the unknown field, got: %v", err)
+	}
+}
+
+func TestLoadConfigFromFile_NotFound(t *testing.T) {
+	_, err := LoadConfigFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil {
+		t.Fatal("expected error for missing file, got nil")
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestLoadConfigFromFile_InvalidJSON(t *testing.T) {
+	path := writeConfigFile(t, `{"base_url": `)
+
+	if _, err := LoadConfigFromFile(path); err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"base_url": "https://file.qpay.mn",
+		"username": "file-user",
+		"password": "file-pass",
+		"invoice_code": "FILE_CODE"
+	}`)
+
+	os.Setenv("QPAY_USERNAME", "env-user")
+	os.Setenv("QPAY_CALLBACK_URL", "https://env.example.com/callback")
+	os.Unsetenv("QPAY_BASE_URL")
+	os.Unsetenv("QPAY_PASSWORD")
+	os.Unsetenv("QPAY_INVOICE_CODE")
+	defer os.Unsetenv("QPAY_USERNAME")
+	defer os.Unsetenv("QPAY_CALLBACK_URL")
+
+	cfg, err := LoadConfig(
+		ConfigFromValues(Config{BaseURL: "https://default.qpay.mn", APIVersion: "v2"}),
+		ConfigFromFile(path),
+		ConfigFromEnv(),
+		ConfigFromValues(Config{InvoiceCode: "OVERRIDE_CODE"}),
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	expected := Config{
+		BaseURL:     "https://file.qpay.mn",             // file overrides default
+		Username:    "env-user",                         // env overrides file
+		Password:    "file-pass",                        // empty env keeps file value
+		InvoiceCode: "OVERRIDE_CODE",                    // explicit override wins
+		CallbackURL: "https://env.example.com/callback", // only set by env
+		APIVersion:  "v2",                               // only set by default
+	}
+	if *cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, *cfg)
+	}
+}
+
+func TestLoadConfig_MergesRequestTimeout(t *testing.T) {
+	base := Config{BaseURL: "https://merchant.qpay.mn", Username: "u", Password: "p", InvoiceCode: "C", CallbackURL: "https://example.com/cb"}
+	cfg, err := LoadConfig(
+		ConfigFromValues(Config{RequestTimeout: 10 * time.Second}),
+		ConfigFromValues(base),
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.RequestTimeout != 10*time.Second {
+		t.Errorf("expected zero to keep 10s, got %v", cfg.RequestTimeout)
+	}
+}
+
+func TestLoadConfig_ValidatesMergedResult(t *testing.T) {
+	_, err := LoadConfig(
+		ConfigFromValues(Config{BaseURL: "https://merchant.qpay.mn", Username: "u", Password: "p"}),
+		ConfigFromValues(Config{InvoiceCode: "CODE"}),
+	)
+	if err == nil {
+		t.Fatal("expected error for missing callback_url, got nil")
+	}
+	if !strings.Contains(err.Error(), "callback_url") {
+		t.Errorf("error should mention callback_url, got: %v", err)
+	}
+}
+
+func TestLoadConfig_SourceError(t *testing.T) {
+	_, err := LoadConfig(ConfigFromFile(filepath.Join(t.TempDir(), "missing.json")))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got: %v", err)
+	}
+}
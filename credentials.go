@@ -0,0 +1,66 @@
+package qpay
+
+import "context"
+
+// Credentials identify the QPay merchant account a call is made as.
+type Credentials struct {
+	Username    string
+	Password    string
+	InvoiceCode string
+}
+
+type credentialsKey struct{}
+
+// WithCredentials returns a copy of ctx that makes Client calls authenticate
+// as creds instead of the Config credentials. Tokens are cached per username,
+// so tenants sharing one Client never see each other's tokens.
+//
+// When creds.InvoiceCode is set, it is used for invoice requests that leave
+// InvoiceCode empty.
+func WithCredentials(ctx context.Context, creds Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, creds)
+}
+
+// CredentialsFromContext returns the credentials stored in ctx by WithCredentials.
+func CredentialsFromContext(ctx context.Context) (Credentials, bool) {
+	creds, ok := ctx.Value(credentialsKey{}).(Credentials)
+	if !ok || creds.Username == "" {
+		return Credentials{}, false
+	}
+	return creds, true
+}
+
+// basicAuth returns the username and password to authenticate with for ctx.
+func (c *Client) basicAuth(ctx context.Context) (string, string) {
+	if creds, ok := CredentialsFromContext(ctx); ok {
+		return creds.Username, creds.Password
+	}
+	return c.config.Username, c.config.Password
+}
+
+// InvoiceCodeResolver returns the invoice code to use for a request's
+// context, or "" to fall back to the Config's.
+type InvoiceCodeResolver func(ctx context.Context) string
+
+// WithInvoiceCodeResolver makes invoice requests that leave InvoiceCode empty
+// ask resolve for it, e.g. to pick the storefront's code from a tenant ID in
+// ctx. An explicit InvoiceCode on the request wins, then the WithCredentials
+// code, then resolve, then the Config's InvoiceCode.
+func WithInvoiceCodeResolver(resolve InvoiceCodeResolver) Option {
+	return func(c *Client) {
+		c.resolveInvoiceCode = resolve
+	}
+}
+
+// invoiceCode returns the invoice code to use for ctx when a request leaves it empty.
+func (c *Client) invoiceCode(ctx context.Context) string {
+	if creds, ok := CredentialsFromContext(ctx); ok && creds.InvoiceCode != "" {
+		return creds.InvoiceCode
+	}
+	if c.resolveInvoiceCode != nil {
+		if code := c.resolveInvoiceCode(ctx); code != "" {
+			return code
+		}
+	}
+	return c.config.InvoiceCode
+}
@@ -0,0 +1,238 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTenantServer returns a server issuing a token named after the Basic Auth
+// username and echoing the bearer token it receives on API calls.
+func newTenantServer(t *testing.T, tokenCalls map[string]int, mu *sync.Mutex) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/auth/token" {
+			user, _, _ := r.BasicAuth()
+			mu.Lock()
+			tokenCalls[user]++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken:      "token-" + user,
+				RefreshToken:     "refresh-" + user,
+				ExpiresIn:        time.Now().Unix() + 3600,
+				RefreshExpiresIn: time.Now().Unix() + 7200,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentDetail{PaymentID: r.Header.Get("Authorization")})
+	}))
+}
+
+func TestWithCredentials_TokenIsolation(t *testing.T) {
+	var mu sync.Mutex
+	tokenCalls := map[string]int{}
+	server := newTenantServer(t, tokenCalls, &mu)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "default",
+		Password: "pass",
+	}, server.Client())
+
+	tenantA := WithCredentials(context.Background(), Credentials{Username: "tenant-a", Password: "a"})
+	tenantB := WithCredentials(context.Background(), Credentials{Username: "tenant-b", Password: "b"})
+
+	cases := []struct {
+		ctx      context.Context
+		expected string
+	}{
+		{tenantA, "Bearer token-tenant-a"},
+		{tenantB, "Bearer token-tenant-b"},
+		{context.Background(), "Bearer token-default"},
+		{tenantA, "Bearer token-tenant-a"},
+		{tenantB, "Bearer token-tenant-b"},
+	}
+	for i, tc := range cases {
+		payment, err := client.GetPayment(tc.ctx, "pay-1")
+		if err != nil {
+			t.Fatalf("call %d: GetPayment failed: %v", i, err)
+		}
+		if payment.PaymentID != tc.expected {
+			t.Errorf("call %d: expected Authorization %q, got %q", i, tc.expected, payment.PaymentID)
+		}
+	}
+
+	for _, user := range []string{"default", "tenant-a", "tenant-b"} {
+		if tokenCalls[user] != 1 {
+			t.Errorf("expected 1 token call for %s, got %d", user, tokenCalls[user])
+		}
+	}
+	if client.accessToken != "token-default" {
+		t.Errorf("default token overwritten by tenant: got %q", client.accessToken)
+	}
+	if len(client.sessions) != 2 {
+		t.Errorf("expected 2 cached sessions, got %d", len(client.sessions))
+	}
+}
+
+func TestWithCredentials_ConfigUsernameSharesDefaultSession(t *testing.T) {
+	var mu sync.Mutex
+	tokenCalls := map[string]int{}
+	server := newTenantServer(t, tokenCalls, &mu)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "default",
+		Password: "pass",
+	}, server.Client())
+
+	ctx := WithCredentials(context.Background(), Credentials{Username: "default", Password: "pass"})
+	if _, err := client.GetPayment(ctx, "pay-1"); err != nil {
+		t.Fatalf("GetPayment failed: %v", err)
+	}
+	if _, err := client.GetPayment(context.Background(), "pay-1"); err != nil {
+		t.Fatalf("GetPayment failed: %v", err)
+	}
+
+	if tokenCalls["default"] != 1 {
+		t.Errorf("expected 1 token call, got %d", tokenCalls["default"])
+	}
+	if len(client.sessions) != 0 {
+		t.Errorf("expected no extra sessions, got %d", len(client.sessions))
+	}
+}
+
+func TestWithCredentials_GetTokenStoresInTenantSession(t *testing.T) {
+	var mu sync.Mutex
+	tokenCalls := map[string]int{}
+	server := newTenantServer(t, tokenCalls, &mu)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{
+		BaseURL:  server.URL,
+		Username: "default",
+		Password: "pass",
+	}, server.Client())
+
+	ctx := WithCredentials(context.Background(), Credentials{Username: "tenant-a", Password: "a"})
+	if _, err := client.GetToken(ctx); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+
+	if client.accessToken != "" {
+		t.Errorf("default token should be untouched, got %q", client.accessToken)
+	}
+	if s := client.sessions["tenant-a"]; s == nil || s.accessToken != "token-tenant-a" {
+		t.Errorf("expected tenant session to hold 'token-tenant-a', got %+v", s)
+	}
+}
+
+func TestWithCredentials_InvoiceCode(t *testing.T) {
+	var codes []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req CreateSimpleInvoiceRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		codes = append(codes, req.InvoiceCode)
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1"})
+	})
+	defer server.Close()
+
+	tenant := WithCredentials(context.Background(), Credentials{
+		Username:    "user",
+		Password:    "pass",
+		InvoiceCode: "TENANT_INVOICE",
+	})
+
+	calls := []struct {
+		ctx  context.Context
+		code string
+	}{
+		{tenant, ""},
+		{context.Background(), ""},
+		{tenant, "EXPLICIT"},
+	}
+	for _, call := range calls {
+		req := &CreateSimpleInvoiceRequest{InvoiceCode: call.code, SenderInvoiceNo: "ORDER-1", Amount: 100}
+		if _, err := client.CreateSimpleInvoice(call.ctx, req); err != nil {
+			t.Fatalf("CreateSimpleInvoice failed: %v", err)
+		}
+		if req.InvoiceCode != call.code {
+			t.Errorf("request was mutated: InvoiceCode %q", req.InvoiceCode)
+		}
+	}
+
+	expected := []string{"TENANT_INVOICE", "TEST_INVOICE", "EXPLICIT"}
+	for i, code := range expected {
+		if codes[i] != code {
+			t.Errorf("call %d: expected invoice code %q, got %q", i, code, codes[i])
+		}
+	}
+}
+
+func TestCredentialsFromContext(t *testing.T) {
+	if _, ok := CredentialsFromContext(context.Background()); ok {
+		t.Error("expected no credentials in background context")
+	}
+
+	ctx := WithCredentials(context.Background(), Credentials{Password: "no-user"})
+	if _, ok := CredentialsFromContext(ctx); ok {
+		t.Error("credentials without username should be ignored")
+	}
+
+	ctx = WithCredentials(context.Background(), Credentials{Username: "u", Password: "p"})
+	creds, ok := CredentialsFromContext(ctx)
+	if !ok || creds.Username != "u" || creds.Password != "p" {
+		t.Errorf("unexpected credentials: %+v, %v", creds, ok)
+	}
+}
+
+type storefrontKey struct{}
+
+func TestWithInvoiceCodeResolver(t *testing.T) {
+	var codes []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req CreateSimpleInvoiceRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		codes = append(codes, req.InvoiceCode)
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1"})
+	})
+	defer server.Close()
+	WithInvoiceCodeResolver(func(ctx context.Context) string {
+		store, _ := ctx.Value(storefrontKey{}).(string)
+		return map[string]string{"north": "NORTH_INVOICE", "south": "SOUTH_INVOICE"}[store]
+	})(client)
+
+	north := context.WithValue(context.Background(), storefrontKey{}, "north")
+	south := context.WithValue(context.Background(), storefrontKey{}, "south")
+	tenant := WithCredentials(north, Credentials{Username: "user", Password: "pass", InvoiceCode: "TENANT_INVOICE"})
+
+	calls := []struct {
+		ctx  context.Context
+		code string
+	}{
+		{north, ""},
+		{south, ""},
+		{context.Background(), ""}, // resolver returns "": Config default
+		{south, "EXPLICIT"},
+		{tenant, ""},
+	}
+	for _, call := range calls {
+		req := &CreateSimpleInvoiceRequest{InvoiceCode: call.code, SenderInvoiceNo: "ORDER-1", Amount: 100}
+		if _, err := client.CreateSimpleInvoice(call.ctx, req); err != nil {
+			t.Fatalf("CreateSimpleInvoice failed: %v", err)
+		}
+	}
+
+	expected := []string{"NORTH_INVOICE", "SOUTH_INVOICE", "TEST_INVOICE", "EXPLICIT", "TENANT_INVOICE"}
+	for i, code := range expected {
+		if codes[i] != code {
+			t.Errorf("call %d: expected invoice code %q, got %q", i, code, codes[i])
+		}
+	}
+}
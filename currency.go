@@ -0,0 +1,80 @@
+package qpay
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code as used by QPay.
+type Currency string
+
+// Supported currencies.
+const (
+	CurrencyMNT Currency = "MNT"
+	CurrencyUSD Currency = "USD"
+)
+
+// ParseCurrency normalizes s (trimmed, upper-cased) and returns an error if it
+// is not a supported currency.
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	if !c.IsValid() {
+		return "", fmt.Errorf("qpay: unsupported currency %q", s)
+	}
+	return c, nil
+}
+
+// IsValid reports whether c is a supported currency.
+func (c Currency) IsValid() bool {
+	switch c {
+	case CurrencyMNT, CurrencyUSD:
+		return true
+	}
+	return false
+}
+
+// currencyOf normalizes a currency code from a response. QPay omits the
+// currency for domestic payments, so an empty value is treated as MNT.
+// Unsupported codes are returned normalized so callers can still inspect them.
+func currencyOf(s string) Currency {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return CurrencyMNT
+	}
+	return Currency(s)
+}
+
+// CurrencyCode returns the payment currency.
+func (p *PaymentDetail) CurrencyCode() Currency {
+	return currencyOf(p.PaymentCurrency)
+}
+
+// CurrencyCode returns the payment currency.
+func (r *PaymentCheckRow) CurrencyCode() Currency {
+	return currencyOf(r.PaymentCurrency)
+}
+
+// CurrencyCode returns the payment currency.
+func (i *PaymentListItem) CurrencyCode() Currency {
+	return currencyOf(i.PaymentCurrency)
+}
+
+// CurrencyCode returns the currency the card was charged in, which differs
+// from MNT for cross-border transactions. It falls back to Currency when
+// TransactionCurrency is not set.
+func (t *CardTransaction) CurrencyCode() Currency {
+	if t.TransactionCurrency != "" {
+		return currencyOf(t.TransactionCurrency)
+	}
+	return currencyOf(t.Currency)
+}
+
+// CurrencyCode returns the transaction currency.
+func (t *P2PTransaction) CurrencyCode() Currency {
+	return currencyOf(t.Currency)
+}
+
+// CurrencyCode returns the account currency.
+func (a *Account) CurrencyCode() Currency {
+	return currencyOf(a.AccountCurrency)
+}
@@ -0,0 +1,83 @@
+package qpay
+
+import "testing"
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Currency
+		wantErr  bool
+	}{
+		{"MNT", CurrencyMNT, false},
+		{"mnt", CurrencyMNT, false},
+		{" USD ", CurrencyUSD, false},
+		{"EUR", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCurrency(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %q", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCurrency(%q) failed: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCurrency_IsValid(t *testing.T) {
+	if !CurrencyMNT.IsValid() || !CurrencyUSD.IsValid() {
+		t.Error("expected MNT and USD to be valid")
+	}
+	if Currency("mnt").IsValid() {
+		t.Error("expected non-normalized currency to be invalid")
+	}
+}
+
+func TestCurrencyCode_Accessors(t *testing.T) {
+	detail := &PaymentDetail{PaymentCurrency: "mnt"}
+	if got := detail.CurrencyCode(); got != CurrencyMNT {
+		t.Errorf("PaymentDetail: expected MNT, got %q", got)
+	}
+
+	row := &PaymentCheckRow{}
+	if got := row.CurrencyCode(); got != CurrencyMNT {
+		t.Errorf("PaymentCheckRow: expected empty currency to default to MNT, got %q", got)
+	}
+
+	item := &PaymentListItem{PaymentCurrency: "EUR"}
+	if got := item.CurrencyCode(); got != "EUR" || got.IsValid() {
+		t.Errorf("PaymentListItem: expected unsupported 'EUR', got %q", got)
+	}
+
+	p2p := &P2PTransaction{Currency: "MNT"}
+	if got := p2p.CurrencyCode(); got != CurrencyMNT {
+		t.Errorf("P2PTransaction: expected MNT, got %q", got)
+	}
+
+	acc := &Account{AccountCurrency: "usd"}
+	if got := acc.CurrencyCode(); got != CurrencyUSD {
+		t.Errorf("Account: expected USD, got %q", got)
+	}
+}
+
+func TestCardTransaction_CurrencyCode(t *testing.T) {
+	crossBorder := &CardTransaction{IsCrossBorder: true, Currency: "MNT", TransactionCurrency: "USD"}
+	if got := crossBorder.CurrencyCode(); got != CurrencyUSD {
+		t.Errorf("expected transaction currency USD, got %q", got)
+	}
+
+	domestic := &CardTransaction{Currency: "MNT"}
+	if got := domestic.CurrencyCode(); got != CurrencyMNT {
+		t.Errorf("expected fallback currency MNT, got %q", got)
+	}
+}
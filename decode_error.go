@@ -0,0 +1,48 @@
+package qpay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DecodeError is returned when a successful response doesn't decode into the
+// expected type, typically because QPay changed a field's type. It is distinct
+// from *Error, which QPay returns on purpose, so schema drift can be logged and
+// alerted on separately. Body is the raw response, or nil for streamed payment
+// listings (see ListPayments), which are not buffered.
+type DecodeError struct {
+	StatusCode int
+	Type       string // the Go type decoded into, e.g. "qpay.PaymentDetail"
+	Body       []byte
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("qpay: failed to unmarshal response into %s (status %d): %v", e.Type, e.StatusCode, e.Err)
+}
+
+// Unwrap returns the underlying JSON error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError describes a failure to decode body into result.
+func newDecodeError(status int, result interface{}, body []byte, err error) *DecodeError {
+	return &DecodeError{
+		StatusCode: status,
+		Type:       strings.TrimPrefix(fmt.Sprintf("%T", result), "*"),
+		Body:       body,
+		Err:        err,
+	}
+}
+
+// IsDecodeError checks if an error, or any error it wraps, is a *DecodeError.
+func IsDecodeError(err error) (*DecodeError, bool) {
+	var dErr *DecodeError
+	if errors.As(err, &dErr) {
+		return dErr, true
+	}
+	return nil, false
+}
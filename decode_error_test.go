@@ -0,0 +1,62 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeError_SchemaDrift(t *testing.T) {
+	const body = `{"payment_id":"pay-1","payment_amount":5000}`
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	})
+	defer server.Close()
+
+	_, err := client.GetPayment(context.Background(), "pay-1")
+	dErr, ok := IsDecodeError(err)
+	if !ok {
+		t.Fatalf("expected *DecodeError, got %T: %v", err, err)
+	}
+	if dErr.Type != "qpay.PaymentDetail" || dErr.StatusCode != http.StatusOK || string(dErr.Body) != body {
+		t.Errorf("unexpected decode error %+v", dErr)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "payment_amount" {
+		t.Errorf("expected the JSON type error to be wrapped, got %v", dErr.Err)
+	}
+	if _, ok := IsQPayError(err); ok {
+		t.Error("decode errors should not be QPay errors")
+	}
+}
+
+func TestDecodeError_StreamedListing(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"count":"two","rows":[]}`)
+	})
+	defer server.Close()
+
+	_, err := client.ListPayments(context.Background(), &PaymentListRequest{})
+	dErr, ok := IsDecodeError(err)
+	if !ok || dErr.Type != "qpay.PaymentListResponse" || dErr.Body != nil {
+		t.Errorf("expected streamed decode error without body, got %v", err)
+	}
+}
+
+func TestDecodeError_TokenResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"access_token":123}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Username: "u", Password: "p"})
+	_, err := client.GetToken(context.Background())
+	if dErr, ok := IsDecodeError(err); !ok || !strings.Contains(string(dErr.Body), "access_token") {
+		t.Errorf("expected token decode error, got %v", err)
+	}
+}
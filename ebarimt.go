@@ -1,12 +1,27 @@
 package qpay
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Ebarimt receiver types for CreateEbarimtRequest.EbarimtReceiverType.
+const (
+	EbarimtReceiverOrganization = "80"
+	EbarimtReceiverCitizen      = "83"
+)
 
 // CreateEbarimt creates an ebarimt (electronic tax receipt) for a payment.
+// The request is checked with Validate before it is sent.
 // POST /v2/ebarimt_v3/create
 func (c *Client) CreateEbarimt(ctx context.Context, req *CreateEbarimtRequest) (*EbarimtResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	var resp EbarimtResponse
-	if err := c.doRequest(ctx, "POST", "/v2/ebarimt_v3/create", req, &resp); err != nil {
+	if err := c.doRequest(ctx, OpEbarimtCreate, "POST", c.ebarimtPath("/create"), req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -16,8 +31,67 @@ func (c *Client) CreateEbarimt(ctx context.Context, req *CreateEbarimtRequest) (
 // DELETE /v2/ebarimt_v3/{id}
 func (c *Client) CancelEbarimt(ctx context.Context, paymentID string) (*EbarimtResponse, error) {
 	var resp EbarimtResponse
-	if err := c.doRequest(ctx, "DELETE", "/v2/ebarimt_v3/"+paymentID, nil, &resp); err != nil {
+	if err := c.doRequest(ctx, OpEbarimtCancel, "DELETE", c.ebarimtPath("/"+paymentID), nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
+
+// RecreateEbarimtWithReceiver reissues a payment's ebarimt to a new receiver,
+// e.g. when a customer wants the receipt under another phone or company.
+// QPay has no endpoint to resend or edit a receipt, so this cancels the
+// ebarimt and creates it again with receiverType and receiver, keeping the
+// original district and classification codes.
+//
+// The new request is validated before anything is canceled. If the create
+// then fails, the original receipt is recreated from the canceled one and the
+// create error is returned; if that rollback also fails, both errors are
+// returned joined, and the payment is left without an ebarimt. The rollback
+// runs even when ctx is done, bounded by Config.RequestTimeout or
+// DefaultRequestTimeout. The two steps are separate calls, so the swap is not
+// atomic on QPay's side.
+func (c *Client) RecreateEbarimtWithReceiver(ctx context.Context, paymentID, receiverType, receiver string) (*EbarimtResponse, error) {
+	req := &CreateEbarimtRequest{
+		PaymentID:           paymentID,
+		EbarimtReceiverType: receiverType,
+		EbarimtReceiver:     receiver,
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	old, err := c.CancelEbarimt(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("qpay: cancel ebarimt: %w", err)
+	}
+	req.DistrictCode = old.EbarimtDistrictCode
+	req.ClassificationCode = old.ClassificationCode
+
+	resp, err := c.CreateEbarimt(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	// The create may have failed because ctx is done; the rollback must
+	// still run, or the payment keeps no receipt.
+	timeout := c.config.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	rbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	_, rbErr := c.CreateEbarimt(rbCtx, &CreateEbarimtRequest{
+		PaymentID:           paymentID,
+		EbarimtReceiverType: old.EbarimtReceiverType,
+		EbarimtReceiver:     old.EbarimtReceiver,
+		DistrictCode:        old.EbarimtDistrictCode,
+		ClassificationCode:  old.ClassificationCode,
+	})
+	if rbErr != nil {
+		return nil, errors.Join(
+			fmt.Errorf("qpay: create ebarimt: %w", err),
+			fmt.Errorf("qpay: restore original ebarimt: %w", rbErr),
+		)
+	}
+	return nil, fmt.Errorf("qpay: create ebarimt (original restored): %w", err)
+}
@@ -0,0 +1,103 @@
+package qpay
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// EbarimtReconcileTolerance is the largest difference between the summed item
+// taxes and the receipt header that Reconciles still accepts. It absorbs
+// per-line rounding to the smallest currency unit.
+const EbarimtReconcileTolerance = 0.01
+
+// TotalVAT sums VatAmount across BarimtItems. Empty amounts count as zero; a
+// malformed amount returns an error naming the item.
+func (e *EbarimtResponse) TotalVAT() (float64, error) {
+	return e.sumItems("vat_amount", func(item EbarimtItem) string { return item.VatAmount })
+}
+
+// TotalCityTax sums CityTaxAmount across BarimtItems. Empty amounts count as
+// zero; a malformed amount returns an error naming the item.
+func (e *EbarimtResponse) TotalCityTax() (float64, error) {
+	return e.sumItems("city_tax_amount", func(item EbarimtItem) string { return item.CityTaxAmount })
+}
+
+// Reconciles reports whether the item VAT and city tax totals match the
+// header VatAmount and CityTaxAmount within EbarimtReconcileTolerance.
+func (e *EbarimtResponse) Reconciles() (bool, error) {
+	vat, err := e.TotalVAT()
+	if err != nil {
+		return false, err
+	}
+	cityTax, err := e.TotalCityTax()
+	if err != nil {
+		return false, err
+	}
+
+	headerVAT, err := parseOptionalAmount("vat_amount", e.VatAmount)
+	if err != nil {
+		return false, err
+	}
+	headerCityTax, err := parseOptionalAmount("city_tax_amount", e.CityTaxAmount)
+	if err != nil {
+		return false, err
+	}
+
+	return math.Abs(vat-headerVAT) <= EbarimtReconcileTolerance &&
+		math.Abs(cityTax-headerCityTax) <= EbarimtReconcileTolerance, nil
+}
+
+func (e *EbarimtResponse) sumItems(field string, amount func(EbarimtItem) string) (float64, error) {
+	var total float64
+	for i, item := range e.BarimtItems {
+		v, err := parseOptionalAmount(fmt.Sprintf("barimt_items[%d].%s", i, field), amount(item))
+		if err != nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
+}
+
+// EbarimtHistories collects the BarimtHistories of receipts dated in
+// [from, to), ordered by date, e.g. for a tax audit export. A zero from or to
+// leaves that end open. A history is dated by EbarimtDate, or CreatedDate when
+// that is empty; a malformed date returns an error.
+//
+// QPay has no endpoint to list or fetch ebarimts, so the histories can only
+// come from the CreateEbarimt and CancelEbarimt responses you stored.
+func EbarimtHistories(receipts []*EbarimtResponse, from, to time.Time) ([]EbarimtHistory, error) {
+	type dated struct {
+		at time.Time
+		h  EbarimtHistory
+	}
+	var out []dated
+	for i, e := range receipts {
+		if e == nil {
+			continue
+		}
+		for j, h := range e.BarimtHistories {
+			field, s := "ebarimt_date", h.EbarimtDate
+			if s == "" {
+				field, s = "created_date", h.CreatedDate
+			}
+			at, err := parseTime(fmt.Sprintf("receipts[%d].barimt_histories[%d].%s", i, j, field), s)
+			if err != nil {
+				return nil, err
+			}
+			if (!from.IsZero() && at.Before(from)) || (!to.IsZero() && !at.Before(to)) {
+				continue
+			}
+			out = append(out, dated{at, h})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].at.Before(out[j].at) })
+	histories := make([]EbarimtHistory, len(out))
+	for i, d := range out {
+		histories[i] = d.h
+	}
+	return histories, nil
+}
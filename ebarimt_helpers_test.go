@@ -0,0 +1,135 @@
+package qpay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEbarimtResponse() *EbarimtResponse {
+	return &EbarimtResponse{
+		VatAmount:     "1000.00",
+		CityTaxAmount: "200.00",
+		BarimtItems: []EbarimtItem{
+			{VatAmount: "600.00", CityTaxAmount: "120.00"},
+			{VatAmount: "400.00", CityTaxAmount: "80.00"},
+		},
+	}
+}
+
+func TestEbarimtResponse_Totals(t *testing.T) {
+	e := testEbarimtResponse()
+
+	vat, err := e.TotalVAT()
+	if err != nil {
+		t.Fatalf("TotalVAT failed: %v", err)
+	}
+	if vat != 1000 {
+		t.Errorf("expected VAT 1000, got %v", vat)
+	}
+
+	cityTax, err := e.TotalCityTax()
+	if err != nil {
+		t.Fatalf("TotalCityTax failed: %v", err)
+	}
+	if cityTax != 200 {
+		t.Errorf("expected city tax 200, got %v", cityTax)
+	}
+}
+
+func TestEbarimtResponse_TotalsEmpty(t *testing.T) {
+	e := &EbarimtResponse{BarimtItems: []EbarimtItem{{VatAmount: ""}}}
+
+	vat, err := e.TotalVAT()
+	if err != nil || vat != 0 {
+		t.Errorf("expected 0, nil; got %v, %v", vat, err)
+	}
+}
+
+func TestEbarimtResponse_TotalsMalformed(t *testing.T) {
+	e := testEbarimtResponse()
+	e.BarimtItems[1].CityTaxAmount = "abc"
+
+	_, err := e.TotalCityTax()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "barimt_items[1].city_tax_amount") {
+		t.Errorf("expected error to name the item field, got %v", err)
+	}
+}
+
+func TestEbarimtResponse_Reconciles(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(*EbarimtResponse)
+		expected bool
+	}{
+		{"matching", func(e *EbarimtResponse) {}, true},
+		{"within tolerance", func(e *EbarimtResponse) { e.VatAmount = "1000.01" }, true},
+		{"vat mismatch", func(e *EbarimtResponse) { e.VatAmount = "1000.50" }, false},
+		{"city tax mismatch", func(e *EbarimtResponse) { e.BarimtItems[0].CityTaxAmount = "100.00" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEbarimtResponse()
+			tt.mutate(e)
+
+			ok, err := e.Reconciles()
+			if err != nil {
+				t.Fatalf("Reconciles failed: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestEbarimtResponse_ReconcilesMalformedHeader(t *testing.T) {
+	e := testEbarimtResponse()
+	e.VatAmount = "n/a"
+
+	if _, err := e.Reconciles(); err == nil {
+		t.Fatal("expected error for malformed header, got nil")
+	}
+}
+
+func TestEbarimtHistories(t *testing.T) {
+	receipts := []*EbarimtResponse{
+		{BarimtHistories: []EbarimtHistory{
+			{ID: "h3", EbarimtDate: "2024-03-20 10:00:00"},
+			{ID: "h1", EbarimtDate: "2024-01-05 10:00:00"},
+		}},
+		nil,
+		{BarimtHistories: []EbarimtHistory{
+			{ID: "h2", CreatedDate: "2024-02-10T08:00:00Z"},
+			{ID: "late", EbarimtDate: "2024-04-01"},
+		}},
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	got, err := EbarimtHistories(receipts, from, to)
+	if err != nil {
+		t.Fatalf("EbarimtHistories failed: %v", err)
+	}
+	var ids []string
+	for _, h := range got {
+		ids = append(ids, h.ID)
+	}
+	if strings.Join(ids, ",") != "h1,h2,h3" {
+		t.Errorf("expected h1,h2,h3, got %v", ids)
+	}
+
+	if all, _ := EbarimtHistories(receipts, time.Time{}, time.Time{}); len(all) != 4 {
+		t.Errorf("expected open range to return 4 histories, got %d", len(all))
+	}
+
+	receipts[0].BarimtHistories[0].EbarimtDate = "last week"
+	_, err = EbarimtHistories(receipts, from, to)
+	if err == nil || !strings.Contains(err.Error(), "receipts[0].barimt_histories[0].ebarimt_date") {
+		t.Errorf("expected error naming the history, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package qpay
+
+import (
+	"strings"
+	"sync"
+)
+
+// EbarimtIndex maps ebarimt lottery numbers back to the receipts they belong
+// to. The QPay API has no lookup by lottery number, so the index only knows
+// receipts you add to it, e.g. every CreateEbarimt response your service
+// stores. Lottery numbers are matched ignoring case and surrounding spaces.
+//
+// The zero value is ready to use and an EbarimtIndex is safe for concurrent use.
+type EbarimtIndex struct {
+	mu        sync.RWMutex
+	byLottery map[string]*EbarimtResponse
+}
+
+// Add indexes e under its EbarimtLottery and the lottery numbers in its
+// BarimtHistories. Adding a receipt with a known lottery number replaces the
+// earlier entry.
+func (x *EbarimtIndex) Add(e *EbarimtResponse) {
+	if e == nil {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.byLottery == nil {
+		x.byLottery = make(map[string]*EbarimtResponse)
+	}
+	if key := lotteryKey(e.EbarimtLottery); key != "" {
+		x.byLottery[key] = e
+	}
+	for _, h := range e.BarimtHistories {
+		if key := lotteryKey(h.EbarimtLottery); key != "" {
+			x.byLottery[key] = e
+		}
+	}
+}
+
+// Lookup returns the receipt indexed under lottery.
+func (x *EbarimtIndex) Lookup(lottery string) (*EbarimtResponse, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.byLottery[lotteryKey(lottery)]
+	return e, ok
+}
+
+// Len returns the number of indexed lottery numbers.
+func (x *EbarimtIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.byLottery)
+}
+
+func lotteryKey(lottery string) string {
+	return strings.ToUpper(strings.TrimSpace(lottery))
+}
@@ -0,0 +1,72 @@
+package qpay
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestEbarimtIndex_Lookup(t *testing.T) {
+	var idx EbarimtIndex
+
+	receipt := &EbarimtResponse{
+		ID:             "eb-1",
+		EbarimtLottery: "AB 12345678",
+		BarimtHistories: []EbarimtHistory{
+			{EbarimtLottery: "CD 87654321"},
+			{EbarimtLottery: ""},
+		},
+	}
+	idx.Add(receipt)
+	idx.Add(nil)
+
+	for _, lottery := range []string{"AB 12345678", " ab 12345678 ", "CD 87654321"} {
+		got, ok := idx.Lookup(lottery)
+		if !ok || got != receipt {
+			t.Errorf("Lookup(%q): expected eb-1, got %v, %v", lottery, got, ok)
+		}
+	}
+	if _, ok := idx.Lookup("ZZ 00000000"); ok {
+		t.Error("expected unknown lottery to miss")
+	}
+	if idx.Len() != 2 {
+		t.Errorf("expected 2 indexed lotteries, got %d", idx.Len())
+	}
+}
+
+func TestEbarimtIndex_ReplacesEarlierEntry(t *testing.T) {
+	var idx EbarimtIndex
+	idx.Add(&EbarimtResponse{ID: "old", EbarimtLottery: "AB 1"})
+	idx.Add(&EbarimtResponse{ID: "new", EbarimtLottery: "AB 1"})
+
+	got, _ := idx.Lookup("AB 1")
+	if got == nil || got.ID != "new" {
+		t.Errorf("expected latest receipt, got %v", got)
+	}
+}
+
+func TestEbarimtIndex_ZeroValueLookup(t *testing.T) {
+	var idx EbarimtIndex
+	if _, ok := idx.Lookup("AB 1"); ok {
+		t.Error("expected empty index to miss")
+	}
+}
+
+func TestEbarimtIndex_Concurrent(t *testing.T) {
+	var idx EbarimtIndex
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lottery := fmt.Sprintf("AB %d", i)
+			idx.Add(&EbarimtResponse{EbarimtLottery: lottery})
+			idx.Lookup(lottery)
+		}(i)
+	}
+	wg.Wait()
+
+	if idx.Len() != 50 {
+		t.Errorf("expected 50 entries, got %d", idx.Len())
+	}
+}
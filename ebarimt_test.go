@@ -3,7 +3,9 @@ package qpay
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -232,3 +234,130 @@ func TestCancelEbarimt_ServerError(t *testing.T) {
 		t.Errorf("expected status 500, got %d", qErr.StatusCode)
 	}
 }
+
+// newEbarimtSwapServer serves CancelEbarimt with the given original receipt
+// and answers creates with createStatus in turn, recording each create.
+func newEbarimtSwapServer(t *testing.T, original EbarimtResponse, createStatus []int, creates *[]CreateEbarimtRequest, cancels *int) (*Client, func()) {
+	t.Helper()
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			*cancels++
+			json.NewEncoder(w).Encode(original)
+			return
+		}
+		var req CreateEbarimtRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		*creates = append(*creates, req)
+		status := createStatus[len(*creates)-1]
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			json.NewEncoder(w).Encode(map[string]string{"error": "EBARIMT_NOT_REGISTERED", "message": "failed"})
+			return
+		}
+		json.NewEncoder(w).Encode(EbarimtResponse{ID: "eb-new", EbarimtReceiverType: req.EbarimtReceiverType, EbarimtReceiver: req.EbarimtReceiver})
+	})
+	return client, server.Close
+}
+
+func TestRecreateEbarimtWithReceiver_Success(t *testing.T) {
+	var creates []CreateEbarimtRequest
+	var cancels int
+	original := EbarimtResponse{ID: "eb-1", EbarimtReceiverType: EbarimtReceiverCitizen, EbarimtReceiver: "88001122", EbarimtDistrictCode: "3505"}
+	client, closeServer := newEbarimtSwapServer(t, original, []int{200}, &creates, &cancels)
+	defer closeServer()
+
+	resp, err := client.RecreateEbarimtWithReceiver(context.Background(), "PAY-1", EbarimtReceiverOrganization, "1234567")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.EbarimtReceiver != "1234567" || cancels != 1 || len(creates) != 1 {
+		t.Fatalf("unexpected result %+v after %d cancels and %d creates", resp, cancels, len(creates))
+	}
+	if creates[0].DistrictCode != "3505" || creates[0].PaymentID != "PAY-1" {
+		t.Errorf("expected the original district code to be kept, got %+v", creates[0])
+	}
+}
+
+func TestRecreateEbarimtWithReceiver_RollsBack(t *testing.T) {
+	var creates []CreateEbarimtRequest
+	var cancels int
+	original := EbarimtResponse{ID: "eb-1", EbarimtReceiverType: EbarimtReceiverCitizen, EbarimtReceiver: "88001122", EbarimtDistrictCode: "3505", ClassificationCode: "0111"}
+	client, closeServer := newEbarimtSwapServer(t, original, []int{400, 200}, &creates, &cancels)
+	defer closeServer()
+
+	_, err := client.RecreateEbarimtWithReceiver(context.Background(), "PAY-1", EbarimtReceiverOrganization, "1234567")
+	var qErr *Error
+	if !errors.As(err, &qErr) || qErr.Code != ErrEbarimtNotRegistered {
+		t.Fatalf("expected the create error, got %v", err)
+	}
+	if len(creates) != 2 {
+		t.Fatalf("expected a rollback create, got %d creates", len(creates))
+	}
+	want := CreateEbarimtRequest{PaymentID: "PAY-1", EbarimtReceiverType: EbarimtReceiverCitizen, EbarimtReceiver: "88001122", DistrictCode: "3505", ClassificationCode: "0111"}
+	if creates[1] != want {
+		t.Errorf("expected the original receipt to be restored, got %+v", creates[1])
+	}
+	if creates[0].ClassificationCode != "0111" {
+		t.Errorf("expected the new receipt to keep the classification code, got %+v", creates[0])
+	}
+}
+
+func TestRecreateEbarimtWithReceiver_RollsBackAfterDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var creates []CreateEbarimtRequest
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			json.NewEncoder(w).Encode(EbarimtResponse{EbarimtReceiverType: EbarimtReceiverCitizen, EbarimtDistrictCode: "3505"})
+			return
+		}
+		var req CreateEbarimtRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		creates = append(creates, req)
+		if len(creates) == 1 {
+			// The caller gives up while the new receipt is being created.
+			cancel()
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		json.NewEncoder(w).Encode(EbarimtResponse{ID: "eb-restored"})
+	})
+	defer server.Close()
+
+	_, err := client.RecreateEbarimtWithReceiver(ctx, "PAY-1", EbarimtReceiverOrganization, "1234567")
+	if err == nil || !strings.Contains(err.Error(), "original restored") {
+		t.Fatalf("expected the original to be restored, got %v", err)
+	}
+	if len(creates) != 2 || creates[1].DistrictCode != "3505" {
+		t.Errorf("expected a rollback create, got %+v", creates)
+	}
+}
+
+func TestRecreateEbarimtWithReceiver_RollbackFails(t *testing.T) {
+	var creates []CreateEbarimtRequest
+	var cancels int
+	client, closeServer := newEbarimtSwapServer(t, EbarimtResponse{EbarimtReceiverType: EbarimtReceiverCitizen}, []int{400, 500}, &creates, &cancels)
+	defer closeServer()
+
+	_, err := client.RecreateEbarimtWithReceiver(context.Background(), "PAY-1", EbarimtReceiverOrganization, "1234567")
+	if err == nil || !strings.Contains(err.Error(), "restore original ebarimt") {
+		t.Fatalf("expected a joined rollback error, got %v", err)
+	}
+}
+
+func TestRecreateEbarimtWithReceiver_InvalidReceiver(t *testing.T) {
+	var creates []CreateEbarimtRequest
+	var cancels int
+	client, closeServer := newEbarimtSwapServer(t, EbarimtResponse{}, nil, &creates, &cancels)
+	defer closeServer()
+
+	_, err := client.RecreateEbarimtWithReceiver(context.Background(), "PAY-1", EbarimtReceiverOrganization, "not-a-tin")
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if cancels != 0 {
+		t.Error("expected nothing to be canceled for an invalid receiver")
+	}
+}
@@ -0,0 +1,103 @@
+package qpay
+
+import "net/http"
+
+// ErrorCategory is a coarse, framework-neutral classification of an *Error,
+// for mapping QPay errors onto HTTP, gRPC or JSON-RPC errors.
+type ErrorCategory int
+
+// Error categories. The zero value, CategoryUnknown, is returned for
+// responses that are not errors at all.
+const (
+	CategoryUnknown     ErrorCategory = iota
+	CategoryAuth                      // credentials missing or rejected
+	CategoryPermission                // authenticated but not allowed, or account inactive
+	CategoryNotFound                  // the resource does not exist
+	CategoryValidation                // the request was malformed or invalid
+	CategoryConflict                  // the resource's state forbids the operation
+	CategoryRateLimited               // a quota was exceeded
+	CategoryTransient                 // a temporary upstream failure; retrying may succeed
+	CategoryInternal                  // an unexpected server error
+)
+
+var categoryNames = [...]string{
+	CategoryUnknown:     "unknown",
+	CategoryAuth:        "auth",
+	CategoryPermission:  "permission",
+	CategoryNotFound:    "not_found",
+	CategoryValidation:  "validation",
+	CategoryConflict:    "conflict",
+	CategoryRateLimited: "rate_limited",
+	CategoryTransient:   "transient",
+	CategoryInternal:    "internal",
+}
+
+// String returns the category's snake_case name.
+func (c ErrorCategory) String() string {
+	if c < 0 || int(c) >= len(categoryNames) {
+		return "unknown"
+	}
+	return categoryNames[c]
+}
+
+// codeCategories classifies QPay codes whose meaning is more specific than
+// their HTTP status. Codes not listed here are classified by status.
+var codeCategories = map[string]ErrorCategory{
+	ErrAuthenticationFailed: CategoryAuth,
+	ErrNoCredentials:        CategoryAuth,
+
+	ErrPermissionDenied:  CategoryPermission,
+	ErrMerchantInactive:  CategoryPermission,
+	ErrQRAccountInactive: CategoryPermission,
+
+	ErrAccountBankDuplicated:     CategoryConflict,
+	ErrBankMCCAlreadyAdded:       CategoryConflict,
+	ErrClientUsernameDuplicated:  CategoryConflict,
+	ErrCustomerDuplicate:         CategoryConflict,
+	ErrInputCodeRegistered:       CategoryConflict,
+	ErrInvoiceAlreadyCanceled:    CategoryConflict,
+	ErrInvoiceCodeRegistered:     CategoryConflict,
+	ErrInvoicePaid:               CategoryConflict,
+	ErrMerchantAlreadyRegistered: CategoryConflict,
+	ErrPaymentAlreadyCanceled:    CategoryConflict,
+	ErrPaymentNotPaid:            CategoryConflict,
+	ErrQRCodeUsed:                CategoryConflict,
+}
+
+// Category classifies e by its QPay code and, failing that, its HTTP status:
+// 401 is CategoryAuth, 403 CategoryPermission, 404 or any *_NOTFOUND code
+// CategoryNotFound, 409 CategoryConflict, 429 CategoryRateLimited, 408, 502,
+// 503 and 504 CategoryTransient, other 4xx CategoryValidation and other 5xx
+// CategoryInternal. HTML maintenance pages (ErrUpstreamUnavailable) are
+// CategoryTransient whatever their status.
+func (e *Error) Category() ErrorCategory {
+	if e.Code == ErrUpstreamUnavailable {
+		return CategoryTransient
+	}
+	if c, ok := codeCategories[e.Code]; ok {
+		return c
+	}
+	if IsNotFound(e) {
+		return CategoryNotFound
+	}
+	switch s := e.StatusCode; {
+	case s == http.StatusUnauthorized:
+		return CategoryAuth
+	case s == http.StatusForbidden:
+		return CategoryPermission
+	case s == http.StatusConflict:
+		return CategoryConflict
+	case s == http.StatusTooManyRequests:
+		return CategoryRateLimited
+	case s == http.StatusRequestTimeout,
+		s == http.StatusBadGateway,
+		s == http.StatusServiceUnavailable,
+		s == http.StatusGatewayTimeout:
+		return CategoryTransient
+	case s >= 500:
+		return CategoryInternal
+	case s >= 400:
+		return CategoryValidation
+	}
+	return CategoryUnknown
+}
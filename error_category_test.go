@@ -0,0 +1,41 @@
+package qpay
+
+import "testing"
+
+func TestError_Category(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want ErrorCategory
+	}{
+		{&Error{StatusCode: 401, Code: "Unauthorized"}, CategoryAuth},
+		{&Error{StatusCode: 400, Code: ErrAuthenticationFailed}, CategoryAuth},
+		{&Error{StatusCode: 403, Code: "Forbidden"}, CategoryPermission},
+		{&Error{StatusCode: 400, Code: ErrMerchantInactive}, CategoryPermission},
+		{&Error{StatusCode: 400, Code: ErrInvoiceNotFound}, CategoryNotFound},
+		{&Error{StatusCode: 404, Code: "Not Found"}, CategoryNotFound},
+		{&Error{StatusCode: 400, Code: ErrInvalidAmount}, CategoryValidation},
+		{&Error{StatusCode: 400, Code: ErrEbarimtNotRegistered}, CategoryValidation},
+		{&Error{StatusCode: 400, Code: ErrPaymentAlreadyCanceled}, CategoryConflict},
+		{&Error{StatusCode: 400, Code: ErrInvoicePaid}, CategoryConflict},
+		{&Error{StatusCode: 409, Code: "Conflict"}, CategoryConflict},
+		{&Error{StatusCode: 429, Code: "Too Many Requests"}, CategoryRateLimited},
+		{&Error{StatusCode: 503, Code: "Service Unavailable"}, CategoryTransient},
+		{&Error{StatusCode: 200, Code: ErrUpstreamUnavailable}, CategoryTransient},
+		{&Error{StatusCode: 500, Code: "Internal Server Error"}, CategoryInternal},
+		{&Error{StatusCode: 200}, CategoryUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Category(); got != tt.want {
+			t.Errorf("%d %s: expected %v, got %v", tt.err.StatusCode, tt.err.Code, tt.want, got)
+		}
+	}
+}
+
+func TestErrorCategory_String(t *testing.T) {
+	if got := CategoryRateLimited.String(); got != "rate_limited" {
+		t.Errorf("expected rate_limited, got %q", got)
+	}
+	if got := ErrorCategory(99).String(); got != "unknown" {
+		t.Errorf("expected unknown for an out-of-range category, got %q", got)
+	}
+}
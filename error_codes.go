@@ -0,0 +1,76 @@
+package qpay
+
+import "sort"
+
+// errorDescriptions explains each QPay error code constant for support staff.
+// Some codes, e.g. NO_CREDENDIALS, keep QPay's spelling.
+var errorDescriptions = map[string]string{
+	ErrAccountBankDuplicated:       "The bank account is already registered.",
+	ErrAccountSelectionInvalid:     "The transaction's settlement accounts are invalid.",
+	ErrAuthenticationFailed:        "The merchant username or password was rejected.",
+	ErrBankAccountNotFound:         "The settlement bank account does not exist.",
+	ErrBankMCCAlreadyAdded:         "The merchant category code is already added for the bank.",
+	ErrBankMCCNotFound:             "The merchant category code is not registered with the bank.",
+	ErrCardTerminalNotFound:        "No card terminal is set up for the merchant.",
+	ErrClientNotFound:              "The API client does not exist.",
+	ErrClientUsernameDuplicated:    "The API client username is already taken.",
+	ErrCustomerDuplicate:           "The customer is already registered.",
+	ErrCustomerNotFound:            "The customer does not exist.",
+	ErrCustomerRegisterInvalid:     "The customer's register number is invalid.",
+	ErrEbarimtCancelNotSupported:   "The ebarimt cannot be canceled.",
+	ErrEbarimtNotRegistered:        "The merchant is not registered for ebarimt.",
+	ErrEbarimtQRCodeInvalid:        "The ebarimt QR code is invalid.",
+	ErrInformNotFound:              "The notification does not exist.",
+	ErrInputCodeRegistered:         "The input code is already registered.",
+	ErrInputNotFound:               "The input does not exist.",
+	ErrInvalidAmount:               "The amount is not valid.",
+	ErrInvalidObjectType:           "The payment check object type is not valid.",
+	ErrInvoiceAlreadyCanceled:      "The invoice was already canceled.",
+	ErrInvoiceCodeInvalid:          "The invoice code is not valid for the merchant.",
+	ErrInvoiceCodeRegistered:       "An invoice with this sender invoice number already exists.",
+	ErrInvoiceLineRequired:         "The invoice needs at least one line.",
+	ErrInvoiceNotFound:             "The invoice does not exist.",
+	ErrInvoicePaid:                 "The invoice is already paid.",
+	ErrInvoiceReceiverDataAddrReq:  "The invoice receiver's address is required.",
+	ErrInvoiceReceiverDataEmailReq: "The invoice receiver's email is required.",
+	ErrInvoiceReceiverDataPhoneReq: "The invoice receiver's phone number is required.",
+	ErrInvoiceReceiverDataRequired: "Invoice receiver data is required.",
+	ErrMaxAmountErr:                "The amount is above the allowed maximum.",
+	ErrMCCNotFound:                 "The merchant category code does not exist.",
+	ErrMerchantAlreadyRegistered:   "The merchant is already registered.",
+	ErrMerchantInactive:            "The merchant account is not active.",
+	ErrMerchantNotFound:            "The merchant does not exist.",
+	ErrMinAmountErr:                "The amount is below the allowed minimum.",
+	ErrNoCredentials:               "The request carried no credentials.",
+	ErrObjectDataError:             "The object's data is invalid.",
+	ErrP2PTerminalNotFound:         "No P2P terminal is set up for the merchant.",
+	ErrPaymentAlreadyCanceled:      "The payment was already canceled.",
+	ErrPaymentNotPaid:              "The payment has not been paid.",
+	ErrPaymentNotFound:             "The payment does not exist.",
+	ErrPermissionDenied:            "The merchant is not allowed to perform this operation.",
+	ErrQRAccountInactive:           "The QR account is not active.",
+	ErrQRAccountNotFound:           "The QR account does not exist.",
+	ErrQRCodeNotFound:              "The QR code does not exist.",
+	ErrQRCodeUsed:                  "The QR code was already used.",
+	ErrSenderBranchDataRequired:    "Sender branch data is required.",
+	ErrTaxLineRequired:             "The ebarimt invoice needs at least one tax line.",
+	ErrTaxProductCodeRequired:      "The line's tax product code is required.",
+	ErrTransactionNotApproved:      "The transaction was not approved.",
+	ErrTransactionRequired:         "At least one transaction is required.",
+}
+
+// AllErrorCodes returns every QPay error code the SDK defines, sorted.
+func AllErrorCodes() []string {
+	codes := make([]string, 0, len(errorDescriptions))
+	for code := range errorDescriptions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ErrorDescription returns a short English explanation of a QPay error code,
+// or "" for codes the SDK doesn't define.
+func ErrorDescription(code string) string {
+	return errorDescriptions[code]
+}
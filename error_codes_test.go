@@ -0,0 +1,59 @@
+package qpay
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestAllErrorCodes_MatchesConstants keeps the description table in step with
+// the QPay error code constants in errors.go. SDK-generated codes such as
+// ErrUpstreamUnavailable are declared outside that block and left out.
+func TestAllErrorCodes_MatchesConstants(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST || gen.Doc.Text() != "QPay error code constants.\n" {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if !strings.HasPrefix(name.Name, "Err") || i >= len(vs.Values) {
+					continue
+				}
+				if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					code, _ := strconv.Unquote(lit.Value)
+					want = append(want, code)
+				}
+			}
+		}
+	}
+	sort.Strings(want)
+
+	got := AllErrorCodes()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("AllErrorCodes out of date:\ngot  %v\nwant %v", got, want)
+	}
+	for _, code := range got {
+		if ErrorDescription(code) == "" {
+			t.Errorf("no description for %s", code)
+		}
+	}
+}
+
+func TestErrorDescription_Unknown(t *testing.T) {
+	for _, code := range []string{"NOT_A_CODE", ErrUpstreamUnavailable} {
+		if got := ErrorDescription(code); got != "" {
+			t.Errorf("expected empty description for %s, got %q", code, got)
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package qpay
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
 
 // Error represents a QPay API error response.
 type Error struct {
@@ -8,13 +17,128 @@ type Error struct {
 	Code       string `json:"error"`
 	Message    string `json:"message"`
 	RawBody    string `json:"-"`
+
+	// MinAmount and MaxAmount are the limit QPay reported with a
+	// MIN_AMOUNT_ERR or MAX_AMOUNT_ERR, or 0 if it did not include one.
+	MinAmount float64 `json:"-"`
+	MaxAmount float64 `json:"-"`
+
+	// Operation is the client operation that received the error, such as
+	// OpPaymentCheck, or "" for errors built outside the client.
+	Operation Operation `json:"-"`
 }
 
-// Error implements the error interface.
+// Error implements the error interface. The operation, when known, is
+// included as "operation=payment.check".
 func (e *Error) Error() string {
+	if e.Operation != "" {
+		return fmt.Sprintf("qpay: %s - %s (status %d, operation=%s)", e.Code, e.Message, e.StatusCode, e.Operation)
+	}
 	return fmt.Sprintf("qpay: %s - %s (status %d)", e.Code, e.Message, e.StatusCode)
 }
 
+// ErrNoPaidPayment is returned by GetLatestPayment when no PAID payment
+// exists for the object.
+var ErrNoPaidPayment = errors.New("qpay: no paid payment found")
+
+// ErrInvalidTokenResponse is returned when QPay answers an authentication or
+// refresh request successfully but without a usable token.
+var ErrInvalidTokenResponse = errors.New("qpay: invalid token response")
+
+// ErrNoQR is returned by WriteQRImage when the invoice carries neither a
+// decodable QRImage nor a QRText that fits in a QR code.
+var ErrNoQR = errors.New("qpay: invoice has no usable QR image or text")
+
+// ErrNoEbarimtQR is returned by RenderEbarimtQR when the receipt has no
+// EbarimtQRData.
+var ErrNoEbarimtQR = errors.New("qpay: ebarimt has no QR data")
+
+// ErrUpstreamUnavailable is the Error.Code the SDK sets, not QPay, when QPay
+// answers with an HTML page, such as its maintenance notice, instead of JSON.
+const ErrUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+
+// ValidationError reports a request rejected locally, before it was sent to QPay.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("qpay: invalid %s: %s", e.Field, e.Message)
+}
+
+// maxErrorMessageLen bounds Error.Message, which falls back to the raw body,
+// so a huge error page doesn't end up in logs in full. RawBody keeps it all.
+const maxErrorMessageLen = 1024
+
+// decodeError builds an *Error from a non-2xx response. Codes and messages
+// missing from the body fall back to the status text and the raw body. It
+// accepts any bytes: Code is never empty and Message is at most
+// maxErrorMessageLen bytes of valid UTF-8.
+func decodeError(statusCode int, body []byte) *Error {
+	qErr := &Error{
+		StatusCode: statusCode,
+		RawBody:    string(body),
+	}
+	_ = json.Unmarshal(body, qErr)
+	if strings.TrimSpace(qErr.Code) == "" {
+		qErr.Code = http.StatusText(statusCode)
+	}
+	if qErr.Code == "" {
+		qErr.Code = fmt.Sprintf("HTTP_%d", statusCode)
+	}
+	if qErr.Message == "" {
+		qErr.Message = string(body)
+	}
+	qErr.Message = truncateMessage(qErr.Message, maxErrorMessageLen)
+	qErr.parseAmountLimit(body)
+	return qErr
+}
+
+// truncateMessage returns s with invalid UTF-8 replaced, cut to at most n
+// bytes on a rune boundary.
+func truncateMessage(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// responseError returns the *Error for a response that cannot be used as a
+// JSON success: an HTML page, such as QPay's maintenance notice, whatever its
+// status (code ErrUpstreamUnavailable), or any other non-2xx status. It
+// returns nil for a usable response.
+func responseError(resp *http.Response, body []byte) *Error {
+	if isHTML(resp.Header.Get("Content-Type"), body) {
+		return &Error{
+			StatusCode: resp.StatusCode,
+			Code:       ErrUpstreamUnavailable,
+			Message:    "QPay returned an HTML page instead of JSON, likely during maintenance",
+			RawBody:    string(body),
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeError(resp.StatusCode, body)
+	}
+	return nil
+}
+
+// isHTML reports whether a response is an HTML page by its media type or,
+// when that is missing or generic, by a body starting with "<".
+func isHTML(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/html" {
+		return true
+	}
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '<'
+}
+
 // IsQPayError checks if an error is a QPay API error and returns it.
 func IsQPayError(err error) (*Error, bool) {
 	if err == nil {
@@ -26,6 +150,56 @@ func IsQPayError(err error) (*Error, bool) {
 	return nil, false
 }
 
+// IsNotFound reports whether err is a QPay error saying the requested
+// resource does not exist: any *_NOTFOUND code (PAYMENT_NOTFOUND,
+// INVOICE_NOTFOUND, CUSTOMER_NOTFOUND, ...) or a 404 status. Wrapped errors
+// are unwrapped.
+func IsNotFound(err error) bool {
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		return false
+	}
+	return qErr.StatusCode == http.StatusNotFound ||
+		strings.HasSuffix(qErr.Code, "_NOTFOUND") ||
+		strings.HasSuffix(qErr.Code, "_NOT_FOUND")
+}
+
+// IsAlreadyCanceled reports whether err is a QPay error saying the payment
+// or invoice was already canceled (PAYMENT_ALREADY_CANCELED or
+// INVOICE_ALREADY_CANCELED). A retried cancel can treat it as success.
+// Wrapped errors are unwrapped.
+func IsAlreadyCanceled(err error) bool {
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		return false
+	}
+	return qErr.Code == ErrPaymentAlreadyCanceled || qErr.Code == ErrInvoiceAlreadyCanceled
+}
+
+// IsInvalidCredentials reports whether err is QPay rejecting the client's
+// credentials (AUTHENTICATION_FAILED or NO_CREDENDIALS), which retrying
+// will not fix. Wrapped errors are unwrapped.
+func IsInvalidCredentials(err error) bool {
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		return false
+	}
+	return qErr.Code == ErrAuthenticationFailed || qErr.Code == ErrNoCredentials
+}
+
+// IsTokenExpired reports whether err is QPay rejecting an expired access or
+// refresh token: the OAuth invalid_grant code, or an error message saying
+// the token is expired. Re-authenticating resolves it. Wrapped errors are
+// unwrapped.
+func IsTokenExpired(err error) bool {
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		return false
+	}
+	return strings.EqualFold(qErr.Code, "invalid_grant") ||
+		strings.Contains(strings.ToLower(qErr.Message), "token is expired")
+}
+
 // QPay error code constants.
 const (
 	ErrAccountBankDuplicated          = "ACCOUNT_BANK_DUPLICATED"
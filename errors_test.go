@@ -1,11 +1,13 @@
 package qpay
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestError_Error(t *testing.T) {
@@ -191,3 +193,169 @@ func TestErrorConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"payment", &Error{StatusCode: 400, Code: ErrPaymentNotFound}, true},
+		{"invoice", &Error{StatusCode: 400, Code: ErrInvoiceNotFound}, true},
+		{"customer", &Error{StatusCode: 400, Code: ErrCustomerNotFound}, true},
+		{"underscored", &Error{StatusCode: 400, Code: ErrBankMCCNotFound}, true},
+		{"404 status", &Error{StatusCode: 404, Code: "Not Found"}, true},
+		{"wrapped", fmt.Errorf("lookup: %w", &Error{StatusCode: 400, Code: ErrPaymentNotFound}), true},
+		{"other code", &Error{StatusCode: 400, Code: ErrPaymentNotPaid}, false},
+		{"plain error", errors.New("PAYMENT_NOTFOUND"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsNotFound(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIsAlreadyCanceled(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"payment", &Error{StatusCode: 400, Code: ErrPaymentAlreadyCanceled}, true},
+		{"invoice", &Error{StatusCode: 400, Code: ErrInvoiceAlreadyCanceled}, true},
+		{"wrapped", fmt.Errorf("cancel: %w", &Error{StatusCode: 400, Code: ErrInvoiceAlreadyCanceled}), true},
+		{"other code", &Error{StatusCode: 400, Code: ErrInvoicePaid}, false},
+		{"plain error", errors.New("PAYMENT_ALREADY_CANCELED"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsAlreadyCanceled(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestResponseError_HTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"html 200", 200, "text/html; charset=utf-8", "<!DOCTYPE html><html>Maintenance</html>"},
+		{"html 503", 503, "text/html", "<html>Down</html>"},
+		{"sniffed", 200, "application/json", "\n  <html>Maintenance</html>"},
+		{"no content type", 502, "", "<h1>Bad Gateway</h1>"},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Content-Type": {tt.contentType}}}
+		qErr := responseError(resp, []byte(tt.body))
+		if qErr == nil {
+			t.Fatalf("%s: expected an error", tt.name)
+		}
+		if qErr.Code != ErrUpstreamUnavailable || qErr.StatusCode != tt.status || qErr.RawBody != tt.body {
+			t.Errorf("%s: unexpected error %+v", tt.name, qErr)
+		}
+		if qErr.Category() != CategoryTransient {
+			t.Errorf("%s: expected CategoryTransient, got %v", tt.name, qErr.Category())
+		}
+	}
+}
+
+func TestResponseError_JSON(t *testing.T) {
+	ok := &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}}
+	if qErr := responseError(ok, []byte(`{"count":0}`)); qErr != nil {
+		t.Errorf("expected nil for a JSON success, got %v", qErr)
+	}
+	if qErr := responseError(ok, nil); qErr != nil {
+		t.Errorf("expected nil for an empty success, got %v", qErr)
+	}
+
+	bad := &http.Response{StatusCode: 400, Header: http.Header{}}
+	qErr := responseError(bad, []byte(`{"error":"INVALID_AMOUNT","message":"bad"}`))
+	if qErr == nil || qErr.Code != ErrInvalidAmount {
+		t.Errorf("expected INVALID_AMOUNT, got %v", qErr)
+	}
+}
+
+func TestIsInvalidCredentials(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"authentication failed", &Error{StatusCode: 401, Code: ErrAuthenticationFailed}, true},
+		{"no credentials", &Error{StatusCode: 401, Code: ErrNoCredentials}, true},
+		{"wrapped", fmt.Errorf("auth: %w", &Error{StatusCode: 401, Code: ErrAuthenticationFailed}), true},
+		{"expired", &Error{StatusCode: 401, Code: "invalid_grant", Message: "Token is expired"}, false},
+		{"plain 401", &Error{StatusCode: 401, Code: "Unauthorized"}, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsInvalidCredentials(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIsTokenExpired(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid_grant", &Error{StatusCode: 400, Code: "invalid_grant", Message: "Refresh token expired"}, true},
+		{"message", &Error{StatusCode: 401, Code: "Unauthorized", Message: "Token is expired"}, true},
+		{"wrapped", fmt.Errorf("refresh: %w", &Error{StatusCode: 400, Code: "invalid_grant"}), true},
+		{"bad credentials", &Error{StatusCode: 401, Code: ErrAuthenticationFailed, Message: "invalid username or password"}, false},
+		{"plain error", errors.New("token is expired"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsTokenExpired(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDecodeError_BoundsMessage(t *testing.T) {
+	body := bytes.Repeat([]byte("é"), maxErrorMessageLen)
+	qErr := decodeError(http.StatusBadGateway, body)
+	if len(qErr.Message) > maxErrorMessageLen || !utf8.ValidString(qErr.Message) {
+		t.Errorf("expected bounded valid message, got %d bytes", len(qErr.Message))
+	}
+	if qErr.RawBody != string(body) {
+		t.Error("expected RawBody to keep the full body")
+	}
+}
+
+func TestDecodeError_UnknownStatusCode(t *testing.T) {
+	qErr := decodeError(599, []byte(`{"error":"  "}`))
+	if qErr.Code != "HTTP_599" {
+		t.Errorf("expected HTTP_599, got %q", qErr.Code)
+	}
+}
+
+func FuzzDecodeError(f *testing.F) {
+	f.Add(400, []byte(`{"error":"INVOICE_NOTFOUND","message":"Invoice not found"}`))
+	f.Add(400, []byte(`{"error":"MIN_AMOUNT_ERR","message":"Minimum amount is 1,000","min_amount":"1000"}`))
+	f.Add(500, []byte("<html>maintenance</html>"))
+	f.Add(0, []byte(`{"error":{"nested":true}}`))
+	f.Add(599, []byte("\xff\xfe"))
+
+	f.Fuzz(func(t *testing.T, status int, body []byte) {
+		qErr := decodeError(status, body)
+		if qErr.Code == "" {
+			t.Fatal("empty Code")
+		}
+		if len(qErr.Message) > maxErrorMessageLen {
+			t.Fatalf("Message is %d bytes", len(qErr.Message))
+		}
+		if !utf8.ValidString(qErr.Message) {
+			t.Fatal("Message is not valid UTF-8")
+		}
+		_ = qErr.Error()
+	})
+}
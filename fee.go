@@ -0,0 +1,85 @@
+package qpay
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FeeRule describes how a fee is charged for one payment type or MCC: a
+// percentage of the amount plus a fixed part, clamped to [Min, Max]. A zero
+// Max means no upper bound.
+type FeeRule struct {
+	Percent float64 // e.g. 1.5 for 1.5%
+	Fixed   float64
+	Min     float64
+	Max     float64
+}
+
+// FeeTable maps payment types (PaymentMethodCard, PaymentMethodP2P, ...) or
+// MCCs to fee rules. Keys are matched case-insensitively.
+type FeeTable map[string]FeeRule
+
+// FeeEstimate is a fee and net breakdown computed by EstimateFee.
+type FeeEstimate struct {
+	Amount      float64
+	Fee         float64
+	Net         float64 // Amount - Fee
+	PaymentType string
+
+	// Approximate is always true: the estimate comes from the local fee
+	// table, not from QPay, and the fee QPay actually charges (PaymentFee on
+	// the payment) may differ.
+	Approximate bool
+}
+
+// WithFeeTable sets the fee table EstimateFee computes from. QPay has no fee
+// preview endpoint, so the rates must match your merchant agreement.
+func WithFeeTable(table FeeTable) Option {
+	return func(c *Client) {
+		c.fees = make(FeeTable, len(table))
+		for k, rule := range table {
+			c.fees[strings.ToUpper(strings.TrimSpace(k))] = rule
+		}
+	}
+}
+
+// EstimateFee returns the approximate fee for paying amount with
+// paymentType, a key of the WithFeeTable table, so the breakdown can be
+// shown before an invoice is created. The fee is rounded to 0.01. It makes
+// no request; ctx is accepted so a future QPay fee endpoint can be used
+// without changing callers. An unknown payment type or a non-positive amount
+// returns a *ValidationError.
+func (c *Client) EstimateFee(ctx context.Context, amount float64, paymentType string) (*FeeEstimate, error) {
+	if amount <= 0 {
+		return nil, &ValidationError{Field: "amount", Message: fmt.Sprintf("must be positive, got %v", amount)}
+	}
+	rule, ok := c.fees[strings.ToUpper(strings.TrimSpace(paymentType))]
+	if !ok {
+		return nil, &ValidationError{Field: "payment_type", Message: fmt.Sprintf("no fee rule for %q", paymentType)}
+	}
+
+	fee := rule.fee(amount)
+	return &FeeEstimate{
+		Amount:      amount,
+		Fee:         fee,
+		Net:         amount - fee,
+		PaymentType: paymentType,
+		Approximate: true,
+	}, nil
+}
+
+func (r FeeRule) fee(amount float64) float64 {
+	fee := amount*r.Percent/100 + r.Fixed
+	if fee < r.Min {
+		fee = r.Min
+	}
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+	if fee > amount {
+		fee = amount
+	}
+	return math.Round(fee*100) / 100
+}
@@ -0,0 +1,61 @@
+package qpay
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEstimateFee(t *testing.T) {
+	client := NewClient(&Config{}, WithFeeTable(FeeTable{
+		PaymentMethodCard: {Percent: 2.5, Min: 100},
+		"p2p":             {Percent: 1, Fixed: 50, Max: 500},
+		"5812":            {Percent: 1.234},
+	}))
+
+	tests := []struct {
+		paymentType string
+		amount      float64
+		fee         float64
+	}{
+		{"CARD", 10000, 250},
+		{"card", 1000, 100},    // clamped to Min
+		{"P2P", 10000, 150},    // 1% + 50
+		{" P2P ", 100000, 500}, // clamped to Max
+		{"5812", 1000, 12.34},  // rounded to 0.01
+		{"CARD", 50, 50},       // never more than the amount
+	}
+	for _, tt := range tests {
+		est, err := client.EstimateFee(context.Background(), tt.amount, tt.paymentType)
+		if err != nil {
+			t.Fatalf("%q %v: unexpected error: %v", tt.paymentType, tt.amount, err)
+		}
+		if est.Fee != tt.fee || est.Net != tt.amount-tt.fee || !est.Approximate {
+			t.Errorf("%q %v: expected fee %v, got %+v", tt.paymentType, tt.amount, tt.fee, est)
+		}
+	}
+}
+
+func TestEstimateFee_Errors(t *testing.T) {
+	client := NewClient(&Config{}, WithFeeTable(FeeTable{PaymentMethodCard: {Percent: 2}}))
+
+	tests := []struct {
+		amount      float64
+		paymentType string
+		field       string
+	}{
+		{1000, PaymentMethodWallet, "payment_type"},
+		{0, PaymentMethodCard, "amount"},
+	}
+	for _, tt := range tests {
+		_, err := client.EstimateFee(context.Background(), tt.amount, tt.paymentType)
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) || vErr.Field != tt.field {
+			t.Errorf("%v %q: expected %s ValidationError, got %v", tt.amount, tt.paymentType, tt.field, err)
+		}
+	}
+
+	if _, err := NewClient(&Config{}).EstimateFee(context.Background(), 1000, PaymentMethodCard); err == nil {
+		t.Error("expected an error without a fee table")
+	}
+}
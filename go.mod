@@ -1,3 +1,5 @@
 module github.com/qpay-sdk/qpay-go
 
 go 1.21
+
+require golang.org/x/time v0.10.0
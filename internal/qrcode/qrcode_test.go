@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestRSRemainder_ISOExample(t *testing.T) {
+	// "HELLO WORLD" as version 1-M data codewords, from ISO/IEC 18004 Annex I.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := rsRemainder(data, rsDivisor(10))
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected ECC %v, got %v", want, got)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		version, bytes int
+	}{
+		{1, 14}, {2, 26}, {7, 122}, {10, 213}, {40, 2331},
+	}
+	for _, tt := range tests {
+		if got := (numDataCodewords(tt.version)*8 - dataBits(0, tt.version)) / 8; got != tt.bytes {
+			t.Errorf("version %d: expected capacity %d bytes, got %d", tt.version, tt.bytes, got)
+		}
+	}
+}
+
+func TestEncode_VersionSelection(t *testing.T) {
+	tests := []struct {
+		n, size int
+	}{
+		{14, 21}, {15, 25}, {2331, 177},
+	}
+	for _, tt := range tests {
+		c, err := Encode(bytes.Repeat([]byte("a"), tt.n))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tt.n, err)
+		}
+		if c.Size != tt.size {
+			t.Errorf("%d bytes: expected size %d, got %d", tt.n, tt.size, c.Size)
+		}
+	}
+
+	if _, err := Encode(make([]byte, 2332)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestEncode_FunctionPatterns(t *testing.T) {
+	c, err := Encode([]byte("https://qpay.mn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Finder pattern rings: dark border, light ring, dark 3x3 center.
+	for _, origin := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 7; x++ {
+				ring := max(abs(x-3), abs(y-3))
+				if want := ring != 2; c.Dark(origin[0]+x, origin[1]+y) != want {
+					t.Fatalf("finder at %v: module (%d,%d) expected dark=%v", origin, x, y, want)
+				}
+			}
+		}
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern wrong at %d", i)
+		}
+	}
+	if !c.Dark(8, c.Size-8) {
+		t.Error("expected the dark module")
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	c := newCode(7)
+	c.drawVersion(7)
+
+	// Version 7 information is 0x07C94 (ISO/IEC 18004 Annex D).
+	var bits int
+	for i := 0; i < 18; i++ {
+		if c.Dark(i/3, c.Size-11+i%3) {
+			bits |= 1 << uint(i)
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("expected version bits 0x07C94, got %#05x", bits)
+	}
+}
+
+func TestImage(t *testing.T) {
+	c, err := Encode([]byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := c.Image(3, 4)
+
+	side := (c.Size + 8) * 3
+	if b := img.Bounds(); b.Dx() != side || b.Dy() != side {
+		t.Fatalf("expected %dx%d image, got %v", side, side, b)
+	}
+	if img.At(0, 0) != color.White {
+		t.Error("expected a light quiet zone")
+	}
+	if img.At(4*3, 4*3) != color.Black {
+		t.Error("expected the top-left finder corner to be dark")
+	}
+}
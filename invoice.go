@@ -1,39 +1,126 @@
 package qpay
 
-import "context"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
 
 // CreateInvoice creates a detailed invoice with full options.
+// An empty InvoiceCode defaults to the context credentials' or Config's invoice code.
+// The request is checked with Validate before it is sent, and with
+// ValidateAmounts if the client was built with WithAmountCheck. With
+// WithInvoiceNoSuffix, an INVOICE_CODE_REGISTERED answer is retried once
+// under a suffixed SenderInvoiceNo; the response's SenderInvoiceNo reports
+// the number QPay accepted.
 // POST /v2/invoice
 func (c *Client) CreateInvoice(ctx context.Context, req *CreateInvoiceRequest) (*InvoiceResponse, error) {
-	var resp InvoiceResponse
-	if err := c.doRequest(ctx, "POST", "/v2/invoice", req, &resp); err != nil {
+	resp, err := c.createDetailedInvoice(ctx, req)
+	qErr, ok := IsQPayError(err)
+	if !c.suffixInvoiceNo || !ok || qErr.Code != ErrInvoiceCodeRegistered || req.SenderInvoiceNo == "" {
+		return resp, err
+	}
+
+	suffix, suffixErr := invoiceNoSuffix()
+	if suffixErr != nil {
+		return nil, errors.Join(err, suffixErr)
+	}
+	r := *req
+	r.SenderInvoiceNo = req.SenderInvoiceNo + "-" + suffix
+	return c.createDetailedInvoice(ctx, &r)
+}
+
+// createDetailedInvoice is CreateInvoice without the WithInvoiceNoSuffix
+// retry, which CreateOrGetInvoice must not use.
+func (c *Client) createDetailedInvoice(ctx context.Context, req *CreateInvoiceRequest) (*InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
 		return nil, err
 	}
-	return &resp, nil
+	if c.checkAmounts {
+		if err := req.ValidateAmounts(); err != nil {
+			return nil, err
+		}
+	}
+	if req.InvoiceCode == "" {
+		r := *req
+		r.InvoiceCode = c.invoiceCode(ctx)
+		req = &r
+	}
+
+	return c.createInvoice(ctx, req.InvoiceCode, req.SenderInvoiceNo, req)
 }
 
 // CreateSimpleInvoice creates a simple invoice with minimal fields.
+// An empty InvoiceCode defaults to the context credentials' or Config's invoice code.
 // POST /v2/invoice
 func (c *Client) CreateSimpleInvoice(ctx context.Context, req *CreateSimpleInvoiceRequest) (*InvoiceResponse, error) {
-	var resp InvoiceResponse
-	if err := c.doRequest(ctx, "POST", "/v2/invoice", req, &resp); err != nil {
-		return nil, err
+	if req.InvoiceCode == "" {
+		r := *req
+		r.InvoiceCode = c.invoiceCode(ctx)
+		req = &r
 	}
-	return &resp, nil
+
+	return c.createInvoice(ctx, req.InvoiceCode, req.SenderInvoiceNo, req)
 }
 
 // CreateEbarimtInvoice creates an invoice with ebarimt (tax) information.
+// An empty InvoiceCode defaults to the context credentials' or Config's invoice code.
 // POST /v2/invoice
 func (c *Client) CreateEbarimtInvoice(ctx context.Context, req *CreateEbarimtInvoiceRequest) (*InvoiceResponse, error) {
-	var resp InvoiceResponse
-	if err := c.doRequest(ctx, "POST", "/v2/invoice", req, &resp); err != nil {
-		return nil, err
+	if req.InvoiceCode == "" {
+		r := *req
+		r.InvoiceCode = c.invoiceCode(ctx)
+		req = &r
 	}
-	return &resp, nil
+
+	return c.createInvoice(ctx, req.InvoiceCode, req.SenderInvoiceNo, req)
 }
 
 // CancelInvoice cancels an existing invoice by ID.
+// QPay doesn't return the canceled invoice: a successful cancel answers 200
+// with an empty body or a short status message, which is ignored. Callers
+// that audit cancellations must record the time themselves.
 // DELETE /v2/invoice/{id}
 func (c *Client) CancelInvoice(ctx context.Context, invoiceID string) error {
-	return c.doRequest(ctx, "DELETE", "/v2/invoice/"+invoiceID, nil, nil)
+	return c.doRequest(ctx, OpInvoiceCancel, "DELETE", c.apiPath("/invoice/"+invoiceID), nil, nil)
+}
+
+// CancelInvoiceWithResult cancels an invoice like CancelInvoice, but reports
+// INVOICE_PAID and INVOICE_ALREADY_CANCELED as result flags instead of errors.
+// An error is returned only for other failures.
+// DELETE /v2/invoice/{id}
+func (c *Client) CancelInvoiceWithResult(ctx context.Context, invoiceID string) (*CancelInvoiceResult, error) {
+	err := c.CancelInvoice(ctx, invoiceID)
+	if err == nil {
+		return &CancelInvoiceResult{Canceled: true}, nil
+	}
+
+	var qErr *Error
+	if errors.As(err, &qErr) {
+		switch qErr.Code {
+		case ErrInvoicePaid:
+			return &CancelInvoiceResult{AlreadyPaid: true}, nil
+		case ErrInvoiceAlreadyCanceled:
+			return &CancelInvoiceResult{AlreadyCanceled: true}, nil
+		}
+	}
+	return nil, err
+}
+
+// Created reports whether QPay created the invoice (201 Created) rather
+// than returning an existing one.
+func (r *InvoiceResponse) Created() bool {
+	return r.StatusCode == http.StatusCreated
+}
+
+// invoiceNoSuffix returns the short random suffix WithInvoiceNoSuffix appends
+// to a registered SenderInvoiceNo.
+func invoiceNoSuffix() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,84 @@
+package qpay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithInvoiceCache makes the create-invoice methods remember each successful
+// response for ttl, keyed by InvoiceCode and SenderInvoiceNo. A repeated create
+// with the same key within ttl returns the remembered invoice without calling
+// QPay. The cache is best-effort and process-local: concurrent duplicates that
+// are both in flight still reach QPay, and other processes don't share it.
+// Requests with an empty SenderInvoiceNo are never cached.
+func WithInvoiceCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.invoices = &invoiceCache{ttl: ttl, entries: make(map[string]invoiceCacheEntry)}
+	}
+}
+
+type invoiceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]invoiceCacheEntry
+}
+
+type invoiceCacheEntry struct {
+	resp    InvoiceResponse
+	expires time.Time
+}
+
+func invoiceCacheKey(invoiceCode, senderInvoiceNo string) string {
+	return invoiceCode + "\x00" + senderInvoiceNo
+}
+
+func (ic *invoiceCache) get(key string, now time.Time) (*InvoiceResponse, bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	e, ok := ic.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+	resp := e.resp
+	resp.URLs = append([]Deeplink(nil), e.resp.URLs...)
+	return &resp, true
+}
+
+func (ic *invoiceCache) put(key string, resp *InvoiceResponse, now time.Time) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	for k, e := range ic.entries {
+		if !now.Before(e.expires) {
+			delete(ic.entries, k)
+		}
+	}
+	stored := *resp
+	stored.URLs = append([]Deeplink(nil), resp.URLs...)
+	ic.entries[key] = invoiceCacheEntry{resp: stored, expires: now.Add(ic.ttl)}
+}
+
+// createInvoice posts an invoice request, consulting the invoice cache when
+// WithInvoiceCache is set.
+func (c *Client) createInvoice(ctx context.Context, invoiceCode, senderInvoiceNo string, req interface{}) (*InvoiceResponse, error) {
+	var key string
+	if c.invoices != nil && senderInvoiceNo != "" {
+		key = invoiceCacheKey(invoiceCode, senderInvoiceNo)
+		if resp, ok := c.invoices.get(key, c.now()); ok {
+			return resp, nil
+		}
+	}
+
+	var resp InvoiceResponse
+	status, err := c.doRequestStatus(ctx, OpInvoiceCreate, "POST", c.apiPath("/invoice"), req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	resp.StatusCode = status
+	resp.SenderInvoiceNo = senderInvoiceNo
+
+	if key != "" {
+		c.invoices.put(key, &resp, c.now())
+	}
+	return &resp, nil
+}
@@ -0,0 +1,97 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithInvoiceCache_ShortCircuitsDuplicates(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: fmt.Sprintf("inv-%d", n)})
+	})
+	defer server.Close()
+	WithInvoiceCache(time.Minute)(client)
+
+	req := &CreateSimpleInvoiceRequest{SenderInvoiceNo: "ORD-1", Amount: 1000}
+	first, err := client.CreateSimpleInvoice(context.Background(), req)
+	if err != nil {
+		t.Fatalf("first create failed: %v", err)
+	}
+	second, err := client.CreateSimpleInvoice(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second create failed: %v", err)
+	}
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("expected 1 invoice request, got %d", calls)
+	}
+	if second.InvoiceID != first.InvoiceID {
+		t.Errorf("expected cached invoice %q, got %q", first.InvoiceID, second.InvoiceID)
+	}
+	if second == first {
+		t.Error("expected cache to return a copy")
+	}
+
+	// A different sender invoice number or invoice code is a different key.
+	if _, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{SenderInvoiceNo: "ORD-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{InvoiceCode: "OTHER", SenderInvoiceNo: "ORD-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("expected 3 invoice requests, got %d", calls)
+	}
+}
+
+func TestWithInvoiceCache_DoesNotCacheErrors(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+	WithInvoiceCache(time.Minute)(client)
+
+	req := &CreateSimpleInvoiceRequest{SenderInvoiceNo: "ORD-1"}
+	client.CreateSimpleInvoice(context.Background(), req)
+	client.CreateSimpleInvoice(context.Background(), req)
+
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected failed creates to reach the server each time, got %d calls", calls)
+	}
+}
+
+func TestInvoiceCache_Expiry(t *testing.T) {
+	ic := &invoiceCache{ttl: time.Minute, entries: make(map[string]invoiceCacheEntry)}
+	now := time.Now()
+	key := invoiceCacheKey("INV", "ORD-1")
+
+	ic.put(key, &InvoiceResponse{InvoiceID: "inv-1", URLs: []Deeplink{{Name: "bank"}}}, now)
+
+	got, ok := ic.get(key, now.Add(30*time.Second))
+	if !ok || got.InvoiceID != "inv-1" {
+		t.Fatalf("expected hit before expiry, got %v, %v", got, ok)
+	}
+	got.URLs[0].Name = "mutated"
+	again, _ := ic.get(key, now)
+	if again.URLs[0].Name != "bank" {
+		t.Error("expected cached URLs to be isolated from callers")
+	}
+
+	if _, ok := ic.get(key, now.Add(time.Minute)); ok {
+		t.Error("expected miss at expiry")
+	}
+
+	ic.put(invoiceCacheKey("INV", "ORD-2"), &InvoiceResponse{}, now.Add(2*time.Minute))
+	if len(ic.entries) != 1 {
+		t.Errorf("expected expired entries to be pruned, got %d", len(ic.entries))
+	}
+}
@@ -0,0 +1,113 @@
+package qpay
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+// BuildEbarimtInvoice returns a CreateEbarimtInvoiceRequest carrying base's
+// invoice code, sender, receiver, description, callback and lines, with the
+// given tax type and district code. Nested structs and tax entries are
+// copied, so the result can be edited without changing base.
+//
+// Ebarimt lines have no discounts or surcharges, so a line that has them gets
+// a unit price that folds them in, keeping the receipt total equal to what is
+// charged: 2 x 5500 less a 500 discount becomes 2 x 5250. If the adjusted
+// total can't be spread over the quantity to the cent, or a line amount is
+// malformed, a *ValidationError is returned. Taxes are copied unchanged.
+// Ebarimt-only fields such as Barcode and ClassificationCode are left for the
+// caller to fill. It returns nil, nil if base is nil.
+func BuildEbarimtInvoice(base *CreateInvoiceRequest, taxType TaxType, districtCode string) (*CreateEbarimtInvoiceRequest, error) {
+	if base == nil {
+		return nil, nil
+	}
+
+	req := &CreateEbarimtInvoiceRequest{
+		InvoiceCode:         base.InvoiceCode,
+		SenderInvoiceNo:     base.SenderInvoiceNo,
+		SenderBranchCode:    base.SenderBranchCode,
+		SenderStaffCode:     base.SenderStaffCode,
+		InvoiceReceiverCode: base.InvoiceReceiverCode,
+		InvoiceDescription:  base.InvoiceDescription,
+		TaxType:             taxType,
+		DistrictCode:        districtCode,
+		CallbackURL:         base.CallbackURL,
+	}
+	if base.SenderStaffData != nil {
+		staff := *base.SenderStaffData
+		req.SenderStaffData = &staff
+	}
+	if base.InvoiceReceiverData != nil {
+		receiver := *base.InvoiceReceiverData
+		if receiver.Address != nil {
+			address := *receiver.Address
+			receiver.Address = &address
+		}
+		req.InvoiceReceiverData = &receiver
+	}
+
+	if len(base.Lines) > 0 {
+		req.Lines = make([]EbarimtInvoiceLine, len(base.Lines))
+	}
+	for i, line := range base.Lines {
+		price, err := line.ebarimtUnitPrice(i)
+		if err != nil {
+			return nil, err
+		}
+		req.Lines[i] = EbarimtInvoiceLine{
+			TaxProductCode:  line.TaxProductCode,
+			LineDescription: line.LineDescription,
+			LineQuantity:    line.LineQuantity,
+			LineUnitPrice:   price,
+			Note:            line.Note,
+			Taxes:           append([]TaxEntry(nil), line.Taxes...),
+		}
+	}
+	return req, nil
+}
+
+// ebarimtUnitPrice returns the line's unit price with its discounts and
+// surcharges folded in, or LineUnitPrice unchanged if it has none. i is the
+// line's index, used in error fields.
+func (l InvoiceLine) ebarimtUnitPrice(i int) (string, error) {
+	if len(l.Discounts) == 0 && len(l.Surcharges) == 0 {
+		return l.LineUnitPrice, nil
+	}
+	total, err := l.total(i)
+	if err != nil {
+		return "", err
+	}
+	qty, err := validateAmount(fmt.Sprintf("lines[%d].line_quantity", i), l.LineQuantity)
+	if err != nil {
+		return "", err
+	}
+	field := fmt.Sprintf("lines[%d].line_unit_price", i)
+	if qty <= 0 {
+		return "", &ValidationError{Field: field, Message: "discounts and surcharges need a positive quantity"}
+	}
+	price := math.Round(total/qty*100) / 100
+	if math.Abs(price*qty-total) > AmountTolerance {
+		return "", &ValidationError{Field: field, Message: fmt.Sprintf("line total %.2f after discounts and surcharges doesn't divide into %v units", total, qty)}
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64), nil
+}
+
+// CallbackURLWithParams returns callbackURL with params added to its query, for
+// carrying reconciliation data such as an internal order ID. QPay echoes the
+// callback URL when it calls back, but doesn't return CreateInvoiceRequest.Note
+// in payment responses, so the callback is where such data round-trips.
+// Existing query parameters are kept; params with the same key replace them.
+func CallbackURLWithParams(callbackURL string, params url.Values) (string, error) {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return "", &ValidationError{Field: "callback_url", Message: err.Error()}
+	}
+	q := u.Query()
+	for k, vs := range params {
+		q[k] = append([]string(nil), vs...)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
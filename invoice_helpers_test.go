@@ -0,0 +1,113 @@
+package qpay
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestBuildEbarimtInvoice(t *testing.T) {
+	base := &CreateInvoiceRequest{
+		InvoiceCode:         "TEST_INVOICE",
+		SenderInvoiceNo:     "ORDER-1",
+		SenderBranchCode:    "BRANCH",
+		SenderStaffCode:     "STAFF",
+		SenderStaffData:     &SenderStaffData{Name: "Bat"},
+		InvoiceReceiverCode: "terminal",
+		InvoiceReceiverData: &InvoiceReceiverData{Name: "Dorj", Address: &Address{City: "UB"}},
+		InvoiceDescription:  "Order 1",
+		Amount:              11000,
+		CallbackURL:         "https://example.com/callback",
+		Lines: []InvoiceLine{{
+			TaxProductCode:  "6401",
+			LineDescription: "Coffee",
+			LineQuantity:    "2",
+			LineUnitPrice:   "5500",
+			Note:            "large",
+			Discounts:       []TaxEntry{{DiscountCode: "D1", Description: "promo", Amount: 500}},
+			Taxes:           []TaxEntry{{TaxCode: "VAT", Description: "VAT", Amount: 1000}},
+		}},
+	}
+
+	got, err := BuildEbarimtInvoice(base, "1", "3505")
+	if err != nil {
+		t.Fatalf("BuildEbarimtInvoice failed: %v", err)
+	}
+
+	want := &CreateEbarimtInvoiceRequest{
+		InvoiceCode:         "TEST_INVOICE",
+		SenderInvoiceNo:     "ORDER-1",
+		SenderBranchCode:    "BRANCH",
+		SenderStaffCode:     "STAFF",
+		SenderStaffData:     &SenderStaffData{Name: "Bat"},
+		InvoiceReceiverCode: "terminal",
+		InvoiceReceiverData: &InvoiceReceiverData{Name: "Dorj", Address: &Address{City: "UB"}},
+		InvoiceDescription:  "Order 1",
+		TaxType:             "1",
+		DistrictCode:        "3505",
+		CallbackURL:         "https://example.com/callback",
+		Lines: []EbarimtInvoiceLine{{
+			TaxProductCode:  "6401",
+			LineDescription: "Coffee",
+			LineQuantity:    "2",
+			LineUnitPrice:   "5250",
+			Note:            "large",
+			Taxes:           []TaxEntry{{TaxCode: "VAT", Description: "VAT", Amount: 1000}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected request:\n got %+v\nwant %+v", got, want)
+	}
+
+	got.SenderStaffData.Name = "changed"
+	got.InvoiceReceiverData.Address.City = "changed"
+	got.Lines[0].Taxes[0].Amount = 0
+	if base.SenderStaffData.Name != "Bat" || base.InvoiceReceiverData.Address.City != "UB" || base.Lines[0].Taxes[0].Amount != 1000 {
+		t.Error("expected the result not to share memory with base")
+	}
+}
+
+func TestBuildEbarimtInvoice_Nil(t *testing.T) {
+	if got, err := BuildEbarimtInvoice(nil, "1", "3505"); got != nil || err != nil {
+		t.Errorf("expected nil, got %+v, %v", got, err)
+	}
+	if got, _ := BuildEbarimtInvoice(&CreateInvoiceRequest{}, "1", "3505"); got.Lines != nil {
+		t.Errorf("expected no lines, got %v", got.Lines)
+	}
+}
+
+func TestBuildEbarimtInvoice_DiscountsAndSurcharges(t *testing.T) {
+	base := &CreateInvoiceRequest{Lines: []InvoiceLine{
+		{LineQuantity: "1", LineUnitPrice: "1000"},
+		{LineQuantity: "4", LineUnitPrice: "250", Surcharges: []TaxEntry{{Amount: 100}}, Discounts: []TaxEntry{{Amount: 50}}},
+	}}
+	got, err := BuildEbarimtInvoice(base, "1", "3505")
+	if err != nil {
+		t.Fatalf("BuildEbarimtInvoice failed: %v", err)
+	}
+	if got.Lines[0].LineUnitPrice != "1000" || got.Lines[1].LineUnitPrice != "262.5" {
+		t.Errorf("unexpected unit prices %q, %q", got.Lines[0].LineUnitPrice, got.Lines[1].LineUnitPrice)
+	}
+
+	base.Lines[1] = InvoiceLine{LineQuantity: "10", LineUnitPrice: "100", Discounts: []TaxEntry{{Amount: 0.04}}}
+	_, err = BuildEbarimtInvoice(base, "1", "3505")
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "lines[1].line_unit_price" {
+		t.Errorf("expected a *ValidationError for an uneven split, got %v", err)
+	}
+}
+
+func TestCallbackURLWithParams(t *testing.T) {
+	got, err := CallbackURLWithParams("https://example.com/callback?shop=1&order=old", url.Values{"order": {"A-42"}})
+	if err != nil {
+		t.Fatalf("CallbackURLWithParams failed: %v", err)
+	}
+	if got != "https://example.com/callback?order=A-42&shop=1" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+
+	if _, err := CallbackURLWithParams("://bad", url.Values{"order": {"1"}}); err == nil {
+		t.Error("expected error for malformed URL")
+	}
+}
@@ -0,0 +1,114 @@
+package qpay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// RenderHTMLOptions customizes the page produced by RenderHTML.
+type RenderHTMLOptions struct {
+	// Title is the page title and heading. Defaults to "QPay".
+	Title string
+	// Description is an optional line shown under the heading.
+	Description string
+}
+
+var invoicePage = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:28rem;margin:2rem auto;padding:0 1rem;text-align:center;color:#222}
+img.qr{width:100%;max-width:20rem;image-rendering:pixelated}
+a.bank{display:block;margin:.5rem 0;padding:.75rem;border-radius:.5rem;background:#f0f2f5;color:#222;text-decoration:none}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<img class="qr" src="{{.QR}}" alt="QPay QR code">
+{{- if .ShortURL}}
+<p><a href="{{.ShortURL}}">{{.ShortURL}}</a></p>
+{{- end}}
+{{- range .Links}}
+<a class="bank" href="{{.Link}}">{{.Name}}</a>
+{{- end}}
+</body>
+</html>
+`))
+
+type invoicePageLink struct {
+	Name string
+	Link template.URL
+}
+
+// RenderHTML returns a minimal self-contained HTML page showing the invoice
+// QR code, embedded as a data URI, and a button per deeplink in URLs, for
+// kiosks and internal tools. The page loads no external assets, so bank
+// logos are not shown. Deeplinks with a javascript:, vbscript: or data:
+// scheme are skipped. opts may be nil. It returns an error if the invoice
+// has no usable QR code, as WriteQRImage does.
+func (r *InvoiceResponse) RenderHTML(opts *RenderHTMLOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &RenderHTMLOptions{}
+	}
+	title := opts.Title
+	if title == "" {
+		title = "QPay"
+	}
+
+	var qr bytes.Buffer
+	if err := r.WriteQRImage(&qr); err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Title, Description, ShortURL string
+		QR                           template.URL
+		Links                        []invoicePageLink
+	}{
+		Title:       title,
+		Description: opts.Description,
+		ShortURL:    r.QPay_ShortURL,
+		QR:          template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(qr.Bytes())),
+	}
+	for _, d := range r.URLs {
+		if !safeDeeplink(d.Link) {
+			continue
+		}
+		name := d.Description
+		if name == "" {
+			name = d.Name
+		}
+		// Deeplinks use app schemes (khanbank://, ...) that html/template
+		// would otherwise replace, so they are passed as trusted URLs.
+		data.Links = append(data.Links, invoicePageLink{Name: name, Link: template.URL(d.Link)})
+	}
+
+	var page bytes.Buffer
+	if err := invoicePage.Execute(&page, data); err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
+
+// safeDeeplink reports whether link is a URL with a scheme that cannot run
+// script when followed.
+func safeDeeplink(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "javascript", "vbscript", "data":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package qpay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInvoiceResponse_RenderHTML(t *testing.T) {
+	inv := &InvoiceResponse{
+		QRText:        "0002010102121531279404962794049600022310027138152045734530349654031005802MN",
+		QPay_ShortURL: "https://s.qpay.mn/abc",
+		URLs: []Deeplink{
+			{Name: "Khan bank", Description: "Хаан банк", Link: "khanbank://q?qPay_QRcode=abc"},
+			{Name: "Bad", Link: "javascript:alert(1)"},
+			{Name: "Relative", Link: "/not-a-deeplink"},
+		},
+	}
+
+	page, err := inv.RenderHTML(&RenderHTMLOptions{Title: "Order <1>", Description: "Scan to pay"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(page)
+
+	for _, want := range []string{
+		"<title>Order &lt;1&gt;</title>",
+		"<p>Scan to pay</p>",
+		`src="data:image/png;base64,`,
+		`<a class="bank" href="khanbank://q?qPay_QRcode=abc">Хаан банк</a>`,
+		`href="https://s.qpay.mn/abc"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"javascript:", "/not-a-deeplink", "ZgotmplZ", "http://", "<script"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("expected page not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestInvoiceResponse_RenderHTML_Defaults(t *testing.T) {
+	page, err := (&InvoiceResponse{QRText: "abc"}).RenderHTML(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(page), "<h1>QPay</h1>") {
+		t.Error("expected the default title")
+	}
+}
+
+func TestInvoiceResponse_RenderHTML_NoQR(t *testing.T) {
+	if _, err := (&InvoiceResponse{}).RenderHTML(nil); !errors.Is(err, ErrNoQR) {
+		t.Errorf("expected ErrNoQR, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package qpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// InvoiceLookup finds an invoice created earlier with invoiceCode and
+// senderInvoiceNo, typically from the caller's own store of InvoiceResponses.
+// It reports ok false when no such invoice is known.
+type InvoiceLookup func(ctx context.Context, invoiceCode, senderInvoiceNo string) (resp *InvoiceResponse, ok bool, err error)
+
+// WithInvoiceLookup sets how CreateOrGetInvoice finds an existing invoice
+// after QPay rejects a create with INVOICE_CODE_REGISTERED.
+func WithInvoiceLookup(lookup InvoiceLookup) Option {
+	return func(c *Client) {
+		c.lookupInvoice = lookup
+	}
+}
+
+// CreateOrGetInvoice creates an invoice like CreateInvoice, but when QPay
+// answers INVOICE_CODE_REGISTERED for a non-empty SenderInvoiceNo it returns
+// the existing invoice instead, making creation idempotent on SenderInvoiceNo.
+//
+// QPay has no endpoint that finds an invoice by SenderInvoiceNo, so the
+// existing invoice comes from the lookup set with WithInvoiceLookup, the only
+// fallback: an invoice in the cache set with WithInvoiceCache is returned
+// before QPay is asked at all. If the lookup doesn't know the invoice, or
+// none is set, the INVOICE_CODE_REGISTERED *Error is returned unchanged.
+// WithInvoiceNoSuffix doesn't apply here.
+func (c *Client) CreateOrGetInvoice(ctx context.Context, req *CreateInvoiceRequest) (*InvoiceResponse, error) {
+	resp, err := c.createDetailedInvoice(ctx, req)
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.Code != ErrInvoiceCodeRegistered || req.SenderInvoiceNo == "" {
+		return resp, err
+	}
+
+	invoiceCode := req.InvoiceCode
+	if invoiceCode == "" {
+		invoiceCode = c.invoiceCode(ctx)
+	}
+	if c.lookupInvoice == nil {
+		return nil, err
+	}
+	existing, found, lookupErr := c.lookupInvoice(ctx, invoiceCode, req.SenderInvoiceNo)
+	if lookupErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("look up existing invoice: %w", lookupErr))
+	}
+	if !found || existing == nil {
+		return nil, err
+	}
+	return existing, nil
+}
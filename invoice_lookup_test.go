@@ -0,0 +1,142 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func registeredHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":   ErrInvoiceCodeRegistered,
+		"message": "Invoice code registered",
+	})
+}
+
+func lookupRequest() *CreateInvoiceRequest {
+	return &CreateInvoiceRequest{
+		InvoiceCode:         "TEST_CODE",
+		SenderInvoiceNo:     "INV-001",
+		InvoiceReceiverCode: "terminal",
+		InvoiceDescription:  "Test invoice",
+		Amount:              50000,
+		CallbackURL:         "https://example.com/callback",
+	}
+}
+
+func TestCreateOrGetInvoice_UsesLookup(t *testing.T) {
+	client, server := newTestClient(t, registeredHandler)
+	defer server.Close()
+
+	var gotCode, gotNo string
+	WithInvoiceLookup(func(ctx context.Context, invoiceCode, senderInvoiceNo string) (*InvoiceResponse, bool, error) {
+		gotCode, gotNo = invoiceCode, senderInvoiceNo
+		return &InvoiceResponse{InvoiceID: "inv-existing"}, true, nil
+	})(client)
+
+	resp, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	if err != nil {
+		t.Fatalf("CreateOrGetInvoice failed: %v", err)
+	}
+	if resp.InvoiceID != "inv-existing" {
+		t.Errorf("expected existing invoice, got %q", resp.InvoiceID)
+	}
+	if gotCode != "TEST_CODE" || gotNo != "INV-001" {
+		t.Errorf("lookup called with %q, %q", gotCode, gotNo)
+	}
+}
+
+func TestCreateOrGetInvoice_NotFoundReturnsOriginalError(t *testing.T) {
+	client, server := newTestClient(t, registeredHandler)
+	defer server.Close()
+
+	WithInvoiceLookup(func(ctx context.Context, invoiceCode, senderInvoiceNo string) (*InvoiceResponse, bool, error) {
+		return nil, false, nil
+	})(client)
+
+	_, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.Code != ErrInvoiceCodeRegistered {
+		t.Fatalf("expected INVOICE_CODE_REGISTERED, got %v", err)
+	}
+}
+
+func TestCreateOrGetInvoice_LookupError(t *testing.T) {
+	client, server := newTestClient(t, registeredHandler)
+	defer server.Close()
+
+	storeErr := errors.New("store down")
+	WithInvoiceLookup(func(ctx context.Context, invoiceCode, senderInvoiceNo string) (*InvoiceResponse, bool, error) {
+		return nil, false, storeErr
+	})(client)
+
+	_, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	if !errors.Is(err, storeErr) {
+		t.Errorf("expected lookup error, got %v", err)
+	}
+	var qErr *Error
+	if !errors.As(err, &qErr) || qErr.Code != ErrInvoiceCodeRegistered {
+		t.Errorf("expected QPay error to be kept, got %v", err)
+	}
+}
+
+func TestCreateOrGetInvoice_OtherErrorsPassThrough(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": ErrInvoiceCodeInvalid})
+	})
+	defer server.Close()
+
+	called := false
+	WithInvoiceLookup(func(ctx context.Context, invoiceCode, senderInvoiceNo string) (*InvoiceResponse, bool, error) {
+		called = true
+		return &InvoiceResponse{}, true, nil
+	})(client)
+
+	_, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	if qErr, ok := IsQPayError(err); !ok || qErr.Code != ErrInvoiceCodeInvalid {
+		t.Errorf("expected INVOICE_CODE_INVALID, got %v", err)
+	}
+	if called {
+		t.Error("lookup should not run for other errors")
+	}
+}
+
+func TestCreateOrGetInvoice_Created(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-new"})
+	})
+	defer server.Close()
+
+	resp, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	if err != nil {
+		t.Fatalf("CreateOrGetInvoice failed: %v", err)
+	}
+	if resp.InvoiceID != "inv-new" {
+		t.Errorf("expected new invoice, got %q", resp.InvoiceID)
+	}
+}
+
+func TestCreateOrGetInvoice_IgnoresInvoiceNoSuffix(t *testing.T) {
+	var creates int
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		creates++
+		registeredHandler(w, r)
+	})
+	defer server.Close()
+	WithInvoiceNoSuffix()(client)
+	WithInvoiceLookup(func(ctx context.Context, invoiceCode, senderInvoiceNo string) (*InvoiceResponse, bool, error) {
+		return &InvoiceResponse{InvoiceID: "inv-existing"}, true, nil
+	})(client)
+
+	resp, err := client.CreateOrGetInvoice(context.Background(), lookupRequest())
+	if err != nil {
+		t.Fatalf("CreateOrGetInvoice failed: %v", err)
+	}
+	if resp.InvoiceID != "inv-existing" || creates != 1 {
+		t.Errorf("expected the existing invoice after 1 create, got %q after %d", resp.InvoiceID, creates)
+	}
+}
@@ -3,7 +3,9 @@ package qpay
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -255,6 +257,20 @@ func TestCancelInvoice_Success(t *testing.T) {
 	}
 }
 
+func TestCancelInvoice_IgnoresStatusBody(t *testing.T) {
+	for _, body := range []string{"", `{"message":"success"}`, "success"} {
+		client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, body)
+		})
+
+		if err := client.CancelInvoice(context.Background(), "inv-123"); err != nil {
+			t.Errorf("body %q: CancelInvoice failed: %v", body, err)
+		}
+		server.Close()
+	}
+}
+
 func TestCancelInvoice_NotFound(t *testing.T) {
 	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -302,3 +318,205 @@ func TestCancelInvoice_AlreadyCanceled(t *testing.T) {
 		t.Errorf("expected code 'INVOICE_ALREADY_CANCELED', got %q", qErr.Code)
 	}
 }
+
+func TestCreateInvoice_Warnings(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"invoice_id":"inv-1","warnings":["sender_staff_code is deprecated"]}`))
+	})
+	defer server.Close()
+
+	resp, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{
+		SenderInvoiceNo: "ORDER-1",
+		Amount:          1000,
+	})
+	if err != nil {
+		t.Fatalf("CreateSimpleInvoice failed: %v", err)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0] != "sender_staff_code is deprecated" {
+		t.Errorf("expected deprecation warning, got %v", resp.Warnings)
+	}
+}
+
+func TestCancelInvoiceWithResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		want   CancelInvoiceResult
+	}{
+		{"canceled", http.StatusOK, "", CancelInvoiceResult{Canceled: true}},
+		{"already paid", http.StatusBadRequest, ErrInvoicePaid, CancelInvoiceResult{AlreadyPaid: true}},
+		{"already canceled", http.StatusBadRequest, ErrInvoiceAlreadyCanceled, CancelInvoiceResult{AlreadyCanceled: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				if tt.code != "" {
+					json.NewEncoder(w).Encode(map[string]string{"error": tt.code, "message": tt.code})
+				}
+			})
+			defer server.Close()
+
+			res, err := client.CancelInvoiceWithResult(context.Background(), "inv-123")
+			if err != nil {
+				t.Fatalf("CancelInvoiceWithResult failed: %v", err)
+			}
+			if *res != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, *res)
+			}
+		})
+	}
+}
+
+func TestCancelInvoiceWithResult_RealFailure(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   ErrInvoiceNotFound,
+			"message": "Invoice not found",
+		})
+	})
+	defer server.Close()
+
+	res, err := client.CancelInvoiceWithResult(context.Background(), "missing")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if res != nil {
+		t.Errorf("expected nil result, got %+v", res)
+	}
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.Code != ErrInvoiceNotFound {
+		t.Errorf("expected INVOICE_NOTFOUND, got %v", err)
+	}
+}
+
+func TestCreateSimpleInvoice_StatusCode(t *testing.T) {
+	for _, status := range []int{http.StatusCreated, http.StatusOK} {
+		client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1"})
+		})
+
+		resp, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{
+			InvoiceCode:         "TEST_CODE",
+			SenderInvoiceNo:     "SINV-001",
+			InvoiceReceiverCode: "terminal",
+			InvoiceDescription:  "Simple test",
+			Amount:              10000,
+			CallbackURL:         "https://example.com/callback",
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("status %d: unexpected error: %v", status, err)
+		}
+		if resp.StatusCode != status {
+			t.Errorf("expected StatusCode %d, got %d", status, resp.StatusCode)
+		}
+		if resp.Created() != (status == http.StatusCreated) {
+			t.Errorf("status %d: unexpected Created() = %v", status, resp.Created())
+		}
+	}
+}
+
+func TestCreateInvoice_NonStandardSuccessStatus(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusIMUsed) // a gateway's idempotent replay
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1"})
+	})
+	defer server.Close()
+
+	var hooked int
+	WithResponseHook(func(req *http.Request, resp *http.Response) {
+		if req.URL.Path == "/v2/invoice" {
+			hooked = resp.StatusCode
+		}
+	})(client)
+
+	invoice, err := client.CreateSimpleInvoice(context.Background(), &CreateSimpleInvoiceRequest{
+		SenderInvoiceNo:     "INV-001",
+		InvoiceReceiverCode: "terminal",
+		InvoiceDescription:  "Test",
+		Amount:              1000,
+		CallbackURL:         "https://example.com/callback",
+	})
+	if err != nil {
+		t.Fatalf("expected 226 to succeed, got %v", err)
+	}
+	if invoice.StatusCode != http.StatusIMUsed || invoice.Created() || invoice.InvoiceID != "inv-1" {
+		t.Errorf("unexpected invoice %+v", invoice)
+	}
+	if hooked != http.StatusIMUsed {
+		t.Errorf("expected the hook to see 226, got %d", hooked)
+	}
+}
+
+func TestCreateInvoice_InvoiceNoSuffix(t *testing.T) {
+	var sent []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body CreateInvoiceRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = append(sent, body.SenderInvoiceNo)
+		if body.SenderInvoiceNo == "INV-001" {
+			registeredHandler(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-suffixed"})
+	})
+	defer server.Close()
+	WithInvoiceNoSuffix()(client)
+
+	req := lookupRequest()
+	resp, err := client.CreateInvoice(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateInvoice failed: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 creates, got %v", sent)
+	}
+	if !strings.HasPrefix(sent[1], "INV-001-") || len(sent[1]) != len("INV-001-")+6 {
+		t.Errorf("expected a suffixed invoice number, got %q", sent[1])
+	}
+	if resp.SenderInvoiceNo != sent[1] {
+		t.Errorf("expected SenderInvoiceNo %q, got %q", sent[1], resp.SenderInvoiceNo)
+	}
+	if req.SenderInvoiceNo != "INV-001" {
+		t.Errorf("request was modified: %q", req.SenderInvoiceNo)
+	}
+}
+
+func TestCreateInvoice_InvoiceNoSuffixRetriesOnce(t *testing.T) {
+	var creates int
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		creates++
+		registeredHandler(w, r)
+	})
+	defer server.Close()
+	WithInvoiceNoSuffix()(client)
+
+	_, err := client.CreateInvoice(context.Background(), lookupRequest())
+	if qErr, ok := IsQPayError(err); !ok || qErr.Code != ErrInvoiceCodeRegistered {
+		t.Fatalf("expected INVOICE_CODE_REGISTERED, got %v", err)
+	}
+	if creates != 2 {
+		t.Errorf("expected 2 creates, got %d", creates)
+	}
+}
+
+func TestCreateInvoice_InvoiceNoSuffixOff(t *testing.T) {
+	var creates int
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		creates++
+		registeredHandler(w, r)
+	})
+	defer server.Close()
+
+	if _, err := client.CreateInvoice(context.Background(), lookupRequest()); err == nil {
+		t.Fatal("expected error")
+	}
+	if creates != 1 {
+		t.Errorf("expected 1 create without WithInvoiceNoSuffix, got %d", creates)
+	}
+}
@@ -18,93 +18,101 @@ type TokenResponse struct {
 
 // Address represents a physical address.
 type Address struct {
-	City      string `json:"city,omitempty"`
-	District  string `json:"district,omitempty"`
-	Street    string `json:"street,omitempty"`
-	Building  string `json:"building,omitempty"`
-	Address   string `json:"address,omitempty"`
-	Zipcode   string `json:"zipcode,omitempty"`
-	Longitude string `json:"longitude,omitempty"`
-	Latitude  string `json:"latitude,omitempty"`
+	City      string `json:"city,omitempty" form:"city"`
+	District  string `json:"district,omitempty" form:"district"`
+	Street    string `json:"street,omitempty" form:"street"`
+	Building  string `json:"building,omitempty" form:"building"`
+	Address   string `json:"address,omitempty" form:"address"`
+	Zipcode   string `json:"zipcode,omitempty" form:"zipcode"`
+	Longitude string `json:"longitude,omitempty" form:"longitude"`
+	Latitude  string `json:"latitude,omitempty" form:"latitude"`
 }
 
 // SenderBranchData represents the sender branch information.
 type SenderBranchData struct {
-	Register string   `json:"register,omitempty"`
-	Name     string   `json:"name,omitempty"`
-	Email    string   `json:"email,omitempty"`
-	Phone    string   `json:"phone,omitempty"`
-	Address  *Address `json:"address,omitempty"`
+	Register string   `json:"register,omitempty" form:"register"`
+	Name     string   `json:"name,omitempty" form:"name"`
+	Email    string   `json:"email,omitempty" form:"email" validate:"omitempty,email"`
+	Phone    string   `json:"phone,omitempty" form:"phone"`
+	Address  *Address `json:"address,omitempty" form:"address"`
 }
 
 // SenderStaffData represents the sender staff information.
 type SenderStaffData struct {
-	Name  string `json:"name,omitempty"`
-	Email string `json:"email,omitempty"`
-	Phone string `json:"phone,omitempty"`
+	Name  string `json:"name,omitempty" form:"name"`
+	Email string `json:"email,omitempty" form:"email" validate:"omitempty,email"`
+	Phone string `json:"phone,omitempty" form:"phone"`
+}
+
+// SenderTerminalData represents the sender terminal (cash register) information.
+// Assign a *SenderTerminalData to CreateInvoiceRequest.SenderTerminalData.
+type SenderTerminalData struct {
+	Name string `json:"name,omitempty" form:"name"`
 }
 
 // InvoiceReceiverData represents the invoice receiver information.
 type InvoiceReceiverData struct {
-	Register string   `json:"register,omitempty"`
-	Name     string   `json:"name,omitempty"`
-	Email    string   `json:"email,omitempty"`
-	Phone    string   `json:"phone,omitempty"`
-	Address  *Address `json:"address,omitempty"`
+	Register string   `json:"register,omitempty" form:"register"`
+	Name     string   `json:"name,omitempty" form:"name"`
+	Email    string   `json:"email,omitempty" form:"email" validate:"omitempty,email"`
+	Phone    string   `json:"phone,omitempty" form:"phone"`
+	Address  *Address `json:"address,omitempty" form:"address"`
 }
 
 // Account represents a bank account used in transactions.
 type Account struct {
-	AccountBankCode string `json:"account_bank_code"`
-	AccountNumber   string `json:"account_number"`
-	IBANNumber      string `json:"iban_number"`
-	AccountName     string `json:"account_name"`
-	AccountCurrency string `json:"account_currency"`
-	IsDefault       bool   `json:"is_default"`
+	AccountBankCode string `json:"account_bank_code" form:"account_bank_code" validate:"required"`
+	AccountNumber   string `json:"account_number" form:"account_number" validate:"required_without=IBANNumber"`
+	IBANNumber      string `json:"iban_number" form:"iban_number" validate:"required_without=AccountNumber"`
+	AccountName     string `json:"account_name" form:"account_name"`
+	AccountCurrency string `json:"account_currency" form:"account_currency" validate:"omitempty,oneof=MNT USD"`
+	IsDefault       bool   `json:"is_default" form:"is_default"`
 }
 
 // Transaction represents a payment transaction.
 type Transaction struct {
-	Description string    `json:"description"`
-	Amount      string    `json:"amount"`
-	Accounts    []Account `json:"accounts,omitempty"`
+	Description string    `json:"description" form:"description" validate:"required"`
+	Amount      string    `json:"amount" form:"amount" validate:"required,numeric"`
+	Accounts    []Account `json:"accounts,omitempty" form:"accounts" validate:"omitempty,dive"`
 }
 
 // InvoiceLine represents a line item in an invoice.
 type InvoiceLine struct {
-	TaxProductCode  string     `json:"tax_product_code,omitempty"`
-	LineDescription string     `json:"line_description"`
-	LineQuantity    string     `json:"line_quantity"`
-	LineUnitPrice   string     `json:"line_unit_price"`
-	Note            string     `json:"note,omitempty"`
-	Discounts       []TaxEntry `json:"discounts,omitempty"`
-	Surcharges      []TaxEntry `json:"surcharges,omitempty"`
-	Taxes           []TaxEntry `json:"taxes,omitempty"`
+	TaxProductCode  string     `json:"tax_product_code,omitempty" form:"tax_product_code"`
+	LineDescription string     `json:"line_description" form:"line_description" validate:"required"`
+	LineQuantity    string     `json:"line_quantity" form:"line_quantity" validate:"required,numeric"`
+	LineUnitPrice   string     `json:"line_unit_price" form:"line_unit_price" validate:"required,numeric"`
+	Note            string     `json:"note,omitempty" form:"note"`
+	Discounts       []TaxEntry `json:"discounts,omitempty" form:"discounts" validate:"omitempty,dive"`
+	Surcharges      []TaxEntry `json:"surcharges,omitempty" form:"surcharges" validate:"omitempty,dive"`
+	Taxes           []TaxEntry `json:"taxes,omitempty" form:"taxes" validate:"omitempty,dive"`
 }
 
 // EbarimtInvoiceLine represents a line item in an ebarimt invoice.
 type EbarimtInvoiceLine struct {
-	TaxProductCode     string     `json:"tax_product_code,omitempty"`
-	LineDescription    string     `json:"line_description"`
-	Barcode            string     `json:"barcode,omitempty"`
-	LineQuantity       string     `json:"line_quantity"`
-	LineUnitPrice      string     `json:"line_unit_price"`
-	Note               string     `json:"note,omitempty"`
-	ClassificationCode string     `json:"classification_code,omitempty"`
-	Taxes              []TaxEntry `json:"taxes,omitempty"`
+	TaxProductCode     string     `json:"tax_product_code,omitempty" form:"tax_product_code"`
+	LineDescription    string     `json:"line_description" form:"line_description" validate:"required"`
+	Barcode            string     `json:"barcode,omitempty" form:"barcode"`
+	LineQuantity       string     `json:"line_quantity" form:"line_quantity" validate:"required,numeric"`
+	LineUnitPrice      string     `json:"line_unit_price" form:"line_unit_price" validate:"required,numeric"`
+	Note               string     `json:"note,omitempty" form:"note"`
+	ClassificationCode string     `json:"classification_code,omitempty" form:"classification_code"`
+	Taxes              []TaxEntry `json:"taxes,omitempty" form:"taxes" validate:"omitempty,dive"`
 }
 
 // TaxEntry represents a tax, discount, or surcharge entry.
 type TaxEntry struct {
-	TaxCode       string  `json:"tax_code,omitempty"`
-	DiscountCode  string  `json:"discount_code,omitempty"`
-	SurchargeCode string  `json:"surcharge_code,omitempty"`
-	Description   string  `json:"description"`
-	Amount        float64 `json:"amount"`
-	Note          string  `json:"note,omitempty"`
+	TaxCode       string  `json:"tax_code,omitempty" form:"tax_code"`
+	DiscountCode  string  `json:"discount_code,omitempty" form:"discount_code"`
+	SurchargeCode string  `json:"surcharge_code,omitempty" form:"surcharge_code"`
+	Description   string  `json:"description" form:"description" validate:"required"`
+	Amount        float64 `json:"amount" form:"amount"`
+	Note          string  `json:"note,omitempty" form:"note"`
 }
 
-// Deeplink represents a payment deeplink for a bank or wallet app.
+// Deeplink represents a payment deeplink for a bank or wallet app. QPay
+// returns deeplinks only in the invoice create response; there is no endpoint
+// to fetch current links for an existing invoice.
 type Deeplink struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
@@ -114,59 +122,69 @@ type Deeplink struct {
 
 // --- Invoice ---
 
+// Request structs carry form tags matching their JSON names and validate tags
+// (go-playground/validator syntax) describing QPay's required fields, so they
+// can be bound and checked by web frameworks. InvoiceCode is not marked
+// required because the client fills it in from the Config when empty.
+
 // CreateInvoiceRequest is the request body for creating a detailed invoice.
+// SenderTerminalData is normally a *SenderTerminalData; any other value, such as
+// a map, is marshaled as is and not checked.
+// Note is stored with the invoice but isn't returned by GetPayment,
+// CheckPayment or ListPayments; see CallbackURLWithParams for reconciliation
+// data that has to come back.
 type CreateInvoiceRequest struct {
-	InvoiceCode          string                `json:"invoice_code"`
-	SenderInvoiceNo      string                `json:"sender_invoice_no"`
-	SenderBranchCode     string                `json:"sender_branch_code,omitempty"`
-	SenderBranchData     *SenderBranchData     `json:"sender_branch_data,omitempty"`
-	SenderStaffData      *SenderStaffData      `json:"sender_staff_data,omitempty"`
-	SenderStaffCode      string                `json:"sender_staff_code,omitempty"`
-	InvoiceReceiverCode  string                `json:"invoice_receiver_code"`
-	InvoiceReceiverData  *InvoiceReceiverData  `json:"invoice_receiver_data,omitempty"`
-	InvoiceDescription   string                `json:"invoice_description"`
-	EnableExpiry         *string               `json:"enable_expiry,omitempty"`
-	AllowPartial         *bool                 `json:"allow_partial,omitempty"`
-	MinimumAmount        *float64              `json:"minimum_amount,omitempty"`
-	AllowExceed          *bool                 `json:"allow_exceed,omitempty"`
-	MaximumAmount        *float64              `json:"maximum_amount,omitempty"`
-	Amount               float64               `json:"amount"`
-	CallbackURL          string                `json:"callback_url"`
-	SenderTerminalCode   *string               `json:"sender_terminal_code,omitempty"`
-	SenderTerminalData   interface{}           `json:"sender_terminal_data,omitempty"`
-	AllowSubscribe       *bool                 `json:"allow_subscribe,omitempty"`
-	SubscriptionInterval string                `json:"subscription_interval,omitempty"`
-	SubscriptionWebhook  string                `json:"subscription_webhook,omitempty"`
-	Note                 *string               `json:"note,omitempty"`
-	Transactions         []Transaction         `json:"transactions,omitempty"`
-	Lines                []InvoiceLine         `json:"lines,omitempty"`
+	InvoiceCode          string               `json:"invoice_code" form:"invoice_code"`
+	SenderInvoiceNo      string               `json:"sender_invoice_no" form:"sender_invoice_no" validate:"required"`
+	SenderBranchCode     string               `json:"sender_branch_code,omitempty" form:"sender_branch_code"`
+	SenderBranchData     *SenderBranchData    `json:"sender_branch_data,omitempty" form:"sender_branch_data"`
+	SenderStaffData      *SenderStaffData     `json:"sender_staff_data,omitempty" form:"sender_staff_data"`
+	SenderStaffCode      string               `json:"sender_staff_code,omitempty" form:"sender_staff_code"`
+	InvoiceReceiverCode  string               `json:"invoice_receiver_code" form:"invoice_receiver_code" validate:"required"`
+	InvoiceReceiverData  *InvoiceReceiverData `json:"invoice_receiver_data,omitempty" form:"invoice_receiver_data"`
+	InvoiceDescription   string               `json:"invoice_description" form:"invoice_description" validate:"required"`
+	EnableExpiry         *string              `json:"enable_expiry,omitempty" form:"enable_expiry"`
+	AllowPartial         *bool                `json:"allow_partial,omitempty" form:"allow_partial"`
+	MinimumAmount        *float64             `json:"minimum_amount,omitempty" form:"minimum_amount" validate:"omitempty,gte=0"`
+	AllowExceed          *bool                `json:"allow_exceed,omitempty" form:"allow_exceed"`
+	MaximumAmount        *float64             `json:"maximum_amount,omitempty" form:"maximum_amount" validate:"omitempty,gte=0"`
+	Amount               float64              `json:"amount" form:"amount" validate:"required,gt=0"`
+	CallbackURL          string               `json:"callback_url" form:"callback_url" validate:"required,url"`
+	SenderTerminalCode   *string              `json:"sender_terminal_code,omitempty" form:"sender_terminal_code"`
+	SenderTerminalData   interface{}          `json:"sender_terminal_data,omitempty" form:"sender_terminal_data"`
+	AllowSubscribe       *bool                `json:"allow_subscribe,omitempty" form:"allow_subscribe"`
+	SubscriptionInterval string               `json:"subscription_interval,omitempty" form:"subscription_interval"`
+	SubscriptionWebhook  string               `json:"subscription_webhook,omitempty" form:"subscription_webhook" validate:"omitempty,url"`
+	Note                 *string              `json:"note,omitempty" form:"note"`
+	Transactions         []Transaction        `json:"transactions,omitempty" form:"transactions" validate:"omitempty,dive"`
+	Lines                []InvoiceLine        `json:"lines,omitempty" form:"lines" validate:"omitempty,dive"`
 }
 
 // CreateSimpleInvoiceRequest is the request body for creating a simple invoice.
 type CreateSimpleInvoiceRequest struct {
-	InvoiceCode         string  `json:"invoice_code"`
-	SenderInvoiceNo     string  `json:"sender_invoice_no"`
-	InvoiceReceiverCode string  `json:"invoice_receiver_code"`
-	InvoiceDescription  string  `json:"invoice_description"`
-	SenderBranchCode    string  `json:"sender_branch_code,omitempty"`
-	Amount              float64 `json:"amount"`
-	CallbackURL         string  `json:"callback_url"`
+	InvoiceCode         string  `json:"invoice_code" form:"invoice_code"`
+	SenderInvoiceNo     string  `json:"sender_invoice_no" form:"sender_invoice_no" validate:"required"`
+	InvoiceReceiverCode string  `json:"invoice_receiver_code" form:"invoice_receiver_code" validate:"required"`
+	InvoiceDescription  string  `json:"invoice_description" form:"invoice_description" validate:"required"`
+	SenderBranchCode    string  `json:"sender_branch_code,omitempty" form:"sender_branch_code"`
+	Amount              float64 `json:"amount" form:"amount" validate:"required,gt=0"`
+	CallbackURL         string  `json:"callback_url" form:"callback_url" validate:"required,url"`
 }
 
 // CreateEbarimtInvoiceRequest is the request body for creating an invoice with ebarimt.
 type CreateEbarimtInvoiceRequest struct {
-	InvoiceCode         string               `json:"invoice_code"`
-	SenderInvoiceNo     string               `json:"sender_invoice_no"`
-	SenderBranchCode    string               `json:"sender_branch_code,omitempty"`
-	SenderStaffData     *SenderStaffData     `json:"sender_staff_data,omitempty"`
-	SenderStaffCode     string               `json:"sender_staff_code,omitempty"`
-	InvoiceReceiverCode string               `json:"invoice_receiver_code"`
-	InvoiceReceiverData *InvoiceReceiverData `json:"invoice_receiver_data,omitempty"`
-	InvoiceDescription  string               `json:"invoice_description"`
-	TaxType             string               `json:"tax_type"`
-	DistrictCode        string               `json:"district_code"`
-	CallbackURL         string               `json:"callback_url"`
-	Lines               []EbarimtInvoiceLine `json:"lines"`
+	InvoiceCode         string               `json:"invoice_code" form:"invoice_code"`
+	SenderInvoiceNo     string               `json:"sender_invoice_no" form:"sender_invoice_no" validate:"required"`
+	SenderBranchCode    string               `json:"sender_branch_code,omitempty" form:"sender_branch_code"`
+	SenderStaffData     *SenderStaffData     `json:"sender_staff_data,omitempty" form:"sender_staff_data"`
+	SenderStaffCode     string               `json:"sender_staff_code,omitempty" form:"sender_staff_code"`
+	InvoiceReceiverCode string               `json:"invoice_receiver_code" form:"invoice_receiver_code" validate:"required"`
+	InvoiceReceiverData *InvoiceReceiverData `json:"invoice_receiver_data,omitempty" form:"invoice_receiver_data"`
+	InvoiceDescription  string               `json:"invoice_description" form:"invoice_description" validate:"required"`
+	TaxType             TaxType              `json:"tax_type" form:"tax_type" validate:"required"`
+	DistrictCode        string               `json:"district_code" form:"district_code" validate:"required"`
+	CallbackURL         string               `json:"callback_url" form:"callback_url" validate:"required,url"`
+	Lines               []EbarimtInvoiceLine `json:"lines" form:"lines" validate:"required,min=1,dive"`
 }
 
 // InvoiceResponse is the response from creating an invoice.
@@ -176,21 +194,42 @@ type InvoiceResponse struct {
 	QRImage       string     `json:"qr_image"`
 	QPay_ShortURL string     `json:"qPay_shortUrl"`
 	URLs          []Deeplink `json:"urls"`
+	Warnings      []string   `json:"warnings,omitempty"`
+
+	// StatusCode is the HTTP status QPay answered the create with: 201 for
+	// a newly created invoice, 200 when an existing one was returned.
+	StatusCode int `json:"-"`
+
+	// SenderInvoiceNo is the sender invoice number the invoice was created
+	// under. It differs from the request's when WithInvoiceNoSuffix retried
+	// with a suffix.
+	SenderInvoiceNo string `json:"-"`
+}
+
+// CancelInvoiceResult is the outcome of CancelInvoiceWithResult. Exactly one
+// field is true.
+type CancelInvoiceResult struct {
+	Canceled        bool // the invoice was canceled by this call
+	AlreadyCanceled bool // QPay answered INVOICE_ALREADY_CANCELED
+	AlreadyPaid     bool // QPay answered INVOICE_PAID; the invoice stays open
 }
 
 // --- Payment ---
 
-// Offset represents pagination parameters.
+// Offset represents pagination parameters. PageNumber is 1-based. When sent
+// through CheckPayment or ListPayments, a zero field takes the client's
+// WithDefaultOffset value, then DefaultPageNumber or DefaultPageLimit, and
+// negative values are rejected with a *ValidationError.
 type Offset struct {
-	PageNumber int `json:"page_number"`
-	PageLimit  int `json:"page_limit"`
+	PageNumber int `json:"page_number" form:"page_number" validate:"gte=0"`
+	PageLimit  int `json:"page_limit" form:"page_limit" validate:"gte=0"`
 }
 
 // PaymentCheckRequest is the request body for checking a payment.
 type PaymentCheckRequest struct {
-	ObjectType string  `json:"object_type"`
-	ObjectID   string  `json:"object_id"`
-	Offset     *Offset `json:"offset,omitempty"`
+	ObjectType string  `json:"object_type" form:"object_type" validate:"required"`
+	ObjectID   string  `json:"object_id" form:"object_id" validate:"required"`
+	Offset     *Offset `json:"offset,omitempty" form:"offset"`
 }
 
 // PaymentCheckResponse is the response from checking a payment.
@@ -198,6 +237,7 @@ type PaymentCheckResponse struct {
 	Count      int               `json:"count"`
 	PaidAmount float64           `json:"paid_amount,omitempty"`
 	Rows       []PaymentCheckRow `json:"rows"`
+	Warnings   []string          `json:"warnings,omitempty"`
 }
 
 // PaymentCheckRow represents a single payment check result row.
@@ -209,6 +249,7 @@ type PaymentCheckRow struct {
 	PaymentCurrency     string            `json:"payment_currency"`
 	PaymentWallet       string            `json:"payment_wallet"`
 	PaymentType         string            `json:"payment_type"`
+	PaymentDate         string            `json:"payment_date,omitempty"`
 	NextPaymentDate     *string           `json:"next_payment_date"`
 	NextPaymentDatetime *string           `json:"next_payment_datetime"`
 	CardTransactions    []CardTransaction `json:"card_transactions"`
@@ -231,6 +272,7 @@ type PaymentDetail struct {
 	NextPaymentDatetime *string           `json:"next_payment_datetime"`
 	CardTransactions    []CardTransaction `json:"card_transactions"`
 	P2PTransactions     []P2PTransaction  `json:"p2p_transactions"`
+	Warnings            []string          `json:"warnings,omitempty"`
 }
 
 // CardTransaction represents a card payment transaction.
@@ -266,17 +308,18 @@ type P2PTransaction struct {
 
 // PaymentListRequest is the request body for listing payments.
 type PaymentListRequest struct {
-	ObjectType string `json:"object_type"`
-	ObjectID   string `json:"object_id"`
-	StartDate  string `json:"start_date"`
-	EndDate    string `json:"end_date"`
-	Offset     Offset `json:"offset"`
+	ObjectType string `json:"object_type" form:"object_type" validate:"required"`
+	ObjectID   string `json:"object_id" form:"object_id" validate:"required"`
+	StartDate  string `json:"start_date" form:"start_date" validate:"required"`
+	EndDate    string `json:"end_date" form:"end_date" validate:"required"`
+	Offset     Offset `json:"offset" form:"offset"`
 }
 
 // PaymentListResponse is the response from listing payments.
 type PaymentListResponse struct {
-	Count int               `json:"count"`
-	Rows  []PaymentListItem `json:"rows"`
+	Count    int               `json:"count"`
+	Rows     []PaymentListItem `json:"rows"`
+	Warnings []string          `json:"warnings,omitempty"`
 }
 
 // PaymentListItem represents a single payment in a list response.
@@ -298,25 +341,25 @@ type PaymentListItem struct {
 
 // PaymentCancelRequest is the request body for canceling a payment.
 type PaymentCancelRequest struct {
-	CallbackURL string `json:"callback_url,omitempty"`
-	Note        string `json:"note,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty" form:"callback_url" validate:"omitempty,url"`
+	Note        string `json:"note,omitempty" form:"note"`
 }
 
 // PaymentRefundRequest is the request body for refunding a payment.
 type PaymentRefundRequest struct {
-	CallbackURL string `json:"callback_url,omitempty"`
-	Note        string `json:"note,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty" form:"callback_url" validate:"omitempty,url"`
+	Note        string `json:"note,omitempty" form:"note"`
 }
 
 // --- Ebarimt ---
 
 // CreateEbarimtRequest is the request body for creating an ebarimt.
 type CreateEbarimtRequest struct {
-	PaymentID           string `json:"payment_id"`
-	EbarimtReceiverType string `json:"ebarimt_receiver_type"`
-	EbarimtReceiver     string `json:"ebarimt_receiver,omitempty"`
-	DistrictCode        string `json:"district_code,omitempty"`
-	ClassificationCode  string `json:"classification_code,omitempty"`
+	PaymentID           string `json:"payment_id" form:"payment_id" validate:"required"`
+	EbarimtReceiverType string `json:"ebarimt_receiver_type" form:"ebarimt_receiver_type" validate:"required"`
+	EbarimtReceiver     string `json:"ebarimt_receiver,omitempty" form:"ebarimt_receiver"`
+	DistrictCode        string `json:"district_code,omitempty" form:"district_code"`
+	ClassificationCode  string `json:"classification_code,omitempty" form:"classification_code"`
 }
 
 // EbarimtResponse is the response from creating or canceling an ebarimt.
@@ -328,6 +371,7 @@ type EbarimtResponse struct {
 	EbarimtReceiverType  string           `json:"ebarimt_receiver_type"`
 	EbarimtReceiver      string           `json:"ebarimt_receiver"`
 	EbarimtDistrictCode  string           `json:"ebarimt_district_code"`
+	ClassificationCode   string           `json:"classification_code,omitempty"`
 	EbarimtBillType      string           `json:"ebarimt_bill_type"`
 	GMerchantID          string           `json:"g_merchant_id"`
 	MerchantBranchCode   string           `json:"merchant_branch_code"`
@@ -348,7 +392,7 @@ type EbarimtResponse struct {
 	BarimtStatusDate     string           `json:"barimt_status_date"`
 	EbarimtSentEmail     *string          `json:"ebarimt_sent_email"`
 	EbarimtReceiverPhone string           `json:"ebarimt_receiver_phone"`
-	TaxType              string           `json:"tax_type"`
+	TaxType              TaxType          `json:"tax_type"`
 	MerchantTIN          string           `json:"merchant_tin,omitempty"`
 	EbarimtReceiptID     string           `json:"ebarimt_receipt_id,omitempty"`
 	CreatedBy            string           `json:"created_by"`
@@ -359,6 +403,7 @@ type EbarimtResponse struct {
 	BarimtItems          []EbarimtItem    `json:"barimt_items,omitempty"`
 	BarimtTransactions   []interface{}    `json:"barimt_transactions,omitempty"`
 	BarimtHistories      []EbarimtHistory `json:"barimt_histories,omitempty"`
+	Warnings             []string         `json:"warnings,omitempty"`
 }
 
 // EbarimtItem represents a single item in an ebarimt receipt.
@@ -406,7 +451,7 @@ type EbarimtHistory struct {
 	BarimtStatusDate     string  `json:"barimt_status_date"`
 	EbarimtSentEmail     *string `json:"ebarimt_sent_email"`
 	EbarimtReceiverPhone string  `json:"ebarimt_receiver_phone"`
-	TaxType              string  `json:"tax_type"`
+	TaxType              TaxType `json:"tax_type"`
 	CreatedBy            string  `json:"created_by"`
 	CreatedDate          string  `json:"created_date"`
 	UpdatedBy            string  `json:"updated_by"`
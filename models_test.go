@@ -0,0 +1,73 @@
+package qpay
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestStructs_FormTagsMatchJSON(t *testing.T) {
+	types := []interface{}{
+		Address{}, SenderBranchData{}, SenderStaffData{}, SenderTerminalData{}, InvoiceReceiverData{},
+		Account{}, Transaction{}, InvoiceLine{}, EbarimtInvoiceLine{}, TaxEntry{},
+		CreateInvoiceRequest{}, CreateSimpleInvoiceRequest{}, CreateEbarimtInvoiceRequest{},
+		Offset{}, PaymentCheckRequest{}, PaymentListRequest{},
+		PaymentCancelRequest{}, PaymentRefundRequest{}, CreateEbarimtRequest{},
+	}
+
+	for _, v := range types {
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if form := field.Tag.Get("form"); form != jsonName {
+				t.Errorf("%s.%s: form tag %q does not match json name %q", typ.Name(), field.Name, form, jsonName)
+			}
+		}
+	}
+}
+
+func TestRequestStructs_RequiredFields(t *testing.T) {
+	required := map[reflect.Type][]string{
+		reflect.TypeOf(CreateInvoiceRequest{}):        {"SenderInvoiceNo", "InvoiceReceiverCode", "InvoiceDescription", "Amount", "CallbackURL"},
+		reflect.TypeOf(CreateSimpleInvoiceRequest{}):  {"SenderInvoiceNo", "InvoiceReceiverCode", "InvoiceDescription", "Amount", "CallbackURL"},
+		reflect.TypeOf(CreateEbarimtInvoiceRequest{}): {"SenderInvoiceNo", "TaxType", "DistrictCode", "Lines"},
+		reflect.TypeOf(PaymentCheckRequest{}):         {"ObjectType", "ObjectID"},
+		reflect.TypeOf(CreateEbarimtRequest{}):        {"PaymentID", "EbarimtReceiverType"},
+	}
+
+	for typ, fields := range required {
+		for _, name := range fields {
+			field, ok := typ.FieldByName(name)
+			if !ok {
+				t.Fatalf("%s has no field %s", typ.Name(), name)
+			}
+			if !strings.HasPrefix(field.Tag.Get("validate"), "required") {
+				t.Errorf("%s.%s: expected required validate tag, got %q", typ.Name(), name, field.Tag.Get("validate"))
+			}
+		}
+	}
+
+	invoiceCode, _ := reflect.TypeOf(CreateInvoiceRequest{}).FieldByName("InvoiceCode")
+	if invoiceCode.Tag.Get("validate") != "" {
+		t.Error("InvoiceCode should not be required; the client fills it from Config")
+	}
+}
+
+func TestSenderTerminalData_Marshal(t *testing.T) {
+	typed, err := json.Marshal(CreateInvoiceRequest{SenderTerminalData: &SenderTerminalData{Name: "Kassa 1"}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	raw, err := json.Marshal(CreateInvoiceRequest{SenderTerminalData: map[string]string{"name": "Kassa 1"}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(typed) != string(raw) {
+		t.Errorf("typed and raw terminal data differ:\n%s\n%s", typed, raw)
+	}
+	if !strings.Contains(string(typed), `"sender_terminal_data":{"name":"Kassa 1"}`) {
+		t.Errorf("unexpected JSON: %s", typed)
+	}
+}
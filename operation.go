@@ -0,0 +1,68 @@
+package qpay
+
+import (
+	"errors"
+	"sync"
+)
+
+// Operation names a QPay API call made by the client, in "area.action" form.
+type Operation string
+
+// Operations the client performs.
+const (
+	OpAuthToken     Operation = "auth.token"
+	OpAuthRefresh   Operation = "auth.refresh"
+	OpAuthValidate  Operation = "auth.validate"
+	OpInvoiceCreate Operation = "invoice.create"
+	OpInvoiceCancel Operation = "invoice.cancel"
+	OpPaymentGet    Operation = "payment.get"
+	OpPaymentCheck  Operation = "payment.check"
+	OpPaymentList   Operation = "payment.list"
+	OpPaymentCancel Operation = "payment.cancel"
+	OpPaymentRefund Operation = "payment.refund"
+	OpEbarimtCreate Operation = "ebarimt.create"
+	OpEbarimtCancel Operation = "ebarimt.cancel"
+)
+
+// WithLastErrors makes the client remember the most recent *Error QPay
+// returned for each Operation, for health endpoints; see LastError. Only one
+// error per operation is kept. Transport failures are not *Error and are not
+// recorded.
+func WithLastErrors() Option {
+	return func(c *Client) {
+		c.lastErrors = &lastErrors{errs: make(map[Operation]*Error)}
+	}
+}
+
+type lastErrors struct {
+	mu   sync.Mutex
+	errs map[Operation]*Error
+}
+
+// LastError returns the most recent QPay error for op, or nil if there has
+// been none or the client was built without WithLastErrors. A later success
+// does not clear it.
+func (c *Client) LastError(op Operation) *Error {
+	if c.lastErrors == nil {
+		return nil
+	}
+	c.lastErrors.mu.Lock()
+	defer c.lastErrors.mu.Unlock()
+	return c.lastErrors.errs[op]
+}
+
+// recordError sets the Operation of err to op if it is an *Error, and
+// remembers it for LastError if WithLastErrors is set.
+func (c *Client) recordError(op Operation, err error) {
+	var qErr *Error
+	if !errors.As(err, &qErr) {
+		return
+	}
+	qErr.Operation = op
+	if c.lastErrors == nil {
+		return
+	}
+	c.lastErrors.mu.Lock()
+	defer c.lastErrors.mu.Unlock()
+	c.lastErrors.errs[op] = qErr
+}
@@ -0,0 +1,82 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLastError_RecordsPerOperation(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": ErrInvoiceCodeInvalid})
+	})
+	defer server.Close()
+	WithLastErrors()(client)
+
+	if client.LastError(OpInvoiceCreate) != nil {
+		t.Fatal("expected no error before any request")
+	}
+
+	_, err := client.CreateInvoice(context.Background(), lookupRequest())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := client.LastError(OpInvoiceCreate); got == nil || got.Code != ErrInvoiceCodeInvalid {
+		t.Errorf("expected INVOICE_CODE_INVALID, got %v", got)
+	}
+	if got := client.LastError(OpPaymentCheck); got != nil {
+		t.Errorf("expected no payment.check error, got %v", got)
+	}
+}
+
+func TestLastError_RecordsAuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": ErrAuthenticationFailed})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Username: "u", Password: "p"}, WithLastErrors())
+	if _, err := client.GetPayment(context.Background(), "pay-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := client.LastError(OpAuthToken); got == nil || got.Code != ErrAuthenticationFailed {
+		t.Errorf("expected AUTHENTICATION_FAILED, got %v", got)
+	}
+}
+
+func TestLastError_DisabledByDefault(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	client.GetPayment(context.Background(), "pay-1")
+	if got := client.LastError(OpPaymentGet); got != nil {
+		t.Errorf("expected nil without WithLastErrors, got %v", got)
+	}
+}
+
+func TestError_Operation(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": ErrInvalidObjectType, "message": "bad object type"})
+	})
+	defer server.Close()
+
+	_, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "NOPE", ObjectID: "inv-1"})
+	qErr, ok := IsQPayError(err)
+	if !ok {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if qErr.Operation != OpPaymentCheck {
+		t.Errorf("expected operation %q, got %q", OpPaymentCheck, qErr.Operation)
+	}
+	if !strings.Contains(err.Error(), "operation=payment.check") {
+		t.Errorf("expected the operation in the message, got %q", err.Error())
+	}
+}
@@ -0,0 +1,63 @@
+package qpay
+
+import (
+	"strings"
+	"time"
+)
+
+// QPay sends null for several optional fields, which decode into nil pointers.
+// The accessors below read them without a nil check at every call site.
+
+// optionalString returns *p trimmed, reporting false for nil or blank values.
+func optionalString(p *string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	s := strings.TrimSpace(*p)
+	return s, s != ""
+}
+
+// nextPayment parses the first of datetime and date that is set.
+func nextPayment(datetime, date *string) (time.Time, bool) {
+	for _, p := range []*string{datetime, date} {
+		s, ok := optionalString(p)
+		if !ok {
+			continue
+		}
+		t, err := parseTime("next_payment_date", s)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// NextPayment returns when the next recurring payment is due, preferring
+// NextPaymentDatetime over NextPaymentDate. It reports false when neither is
+// set or the value can't be parsed.
+func (r *PaymentCheckRow) NextPayment() (time.Time, bool) {
+	return nextPayment(r.NextPaymentDatetime, r.NextPaymentDate)
+}
+
+// NextPayment returns when the next recurring payment is due, preferring
+// NextPaymentDatetime over NextPaymentDate. It reports false when neither is
+// set or the value can't be parsed.
+func (p *PaymentDetail) NextPayment() (time.Time, bool) {
+	return nextPayment(p.NextPaymentDatetime, p.NextPaymentDate)
+}
+
+// TerminalCode returns MerchantTerminalCode, reporting false when it is null
+// or blank.
+func (r *EbarimtResponse) TerminalCode() (string, bool) {
+	return optionalString(r.MerchantTerminalCode)
+}
+
+// StaffCode returns MerchantStaffCode, reporting false when it is null or
+// blank.
+func (r *EbarimtResponse) StaffCode() (string, bool) {
+	return optionalString(r.MerchantStaffCode)
+}
+
+// SentEmail returns the address the receipt was emailed to, reporting false
+// when it wasn't emailed.
+func (r *EbarimtResponse) SentEmail() (string, bool) {
+	return optionalString(r.EbarimtSentEmail)
+}
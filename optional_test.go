@@ -0,0 +1,57 @@
+package qpay
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPaymentCheckRow_NextPayment(t *testing.T) {
+	date, datetime, blank, bad := "2024-04-01", "2024-04-01 09:30:00", " ", "soon"
+	tests := []struct {
+		name   string
+		row    PaymentCheckRow
+		want   time.Time
+		wantOK bool
+	}{
+		{"null", PaymentCheckRow{}, time.Time{}, false},
+		{"date only", PaymentCheckRow{NextPaymentDate: &date}, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), true},
+		{"datetime wins", PaymentCheckRow{NextPaymentDate: &date, NextPaymentDatetime: &datetime}, time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC), true},
+		{"blank datetime", PaymentCheckRow{NextPaymentDate: &date, NextPaymentDatetime: &blank}, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), true},
+		{"malformed", PaymentCheckRow{NextPaymentDate: &bad}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.row.NextPayment()
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("expected %v, %v, got %v, %v", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestPaymentDetail_NextPayment_FromJSON(t *testing.T) {
+	var p PaymentDetail
+	if err := json.Unmarshal([]byte(`{"next_payment_date":null,"next_payment_datetime":"2024-05-01T00:00:00Z"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := p.NextPayment(); !ok || got.Month() != time.May {
+		t.Errorf("expected May, got %v, %v", got, ok)
+	}
+}
+
+func TestEbarimtResponse_OptionalCodes(t *testing.T) {
+	var r EbarimtResponse
+	if err := json.Unmarshal([]byte(`{"merchant_terminal_code":"T-1","merchant_staff_code":null,"ebarimt_sent_email":""}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if code, ok := r.TerminalCode(); !ok || code != "T-1" {
+		t.Errorf("expected T-1, got %q, %v", code, ok)
+	}
+	if _, ok := r.StaffCode(); ok {
+		t.Error("expected null staff code to report false")
+	}
+	if _, ok := r.SentEmail(); ok {
+		t.Error("expected empty email to report false")
+	}
+}
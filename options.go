@@ -0,0 +1,256 @@
+package qpay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures optional Client behavior. Pass options to NewClient or
+// NewClientWithHTTPClient.
+type Option func(*Client)
+
+// ResponseHook is called with every HTTP request the client sends and the
+// response it received, including token and error responses. The response body
+// is already buffered: hooks may read it freely without affecting decoding.
+// The request body has been sent by then; req.GetBody returns a fresh copy of
+// it.
+type ResponseHook func(req *http.Request, resp *http.Response)
+
+// WithResponseHook registers a hook that sees every response, for example to
+// read rate-limit or cache headers. Hooks run in registration order.
+func WithResponseHook(hook ResponseHook) Option {
+	return func(c *Client) {
+		c.responseHooks = append(c.responseHooks, hook)
+	}
+}
+
+// URLRewriter adjusts the URL of an outgoing request in place, e.g. to send
+// it to a regional mirror.
+type URLRewriter func(u *url.URL)
+
+// WithURLRewriter calls rewrite with the URL of every request the client
+// sends, token requests included, after its headers and body are built, so
+// only the destination changes. The Host header follows the rewritten URL.
+// Retries reuse the rewritten URL.
+func WithURLRewriter(rewrite URLRewriter) Option {
+	return func(c *Client) {
+		c.rewriteURL = rewrite
+	}
+}
+
+// applyURLRewriter runs the WithURLRewriter function, if any, on req.
+func (c *Client) applyURLRewriter(req *http.Request) {
+	if c.rewriteURL == nil {
+		return
+	}
+	c.rewriteURL(req.URL)
+	req.Host = req.URL.Host
+}
+
+// WithAmountCheck makes CreateInvoice reject requests whose Transactions or
+// Lines don't add up to Amount, using CreateInvoiceRequest.ValidateAmounts.
+func WithAmountCheck() Option {
+	return func(c *Client) {
+		c.checkAmounts = true
+	}
+}
+
+// WithInvoiceNoSuffix makes CreateInvoice recover from INVOICE_CODE_REGISTERED
+// by retrying once with a short random suffix appended to SenderInvoiceNo,
+// as in "INV-001-3f9a2c". It suits batch jobs that generate invoice numbers;
+// code that relies on SenderInvoiceNo for idempotency should use
+// CreateOrGetInvoice instead, which never adds a suffix.
+func WithInvoiceNoSuffix() Option {
+	return func(c *Client) {
+		c.suffixInvoiceNo = true
+	}
+}
+
+// WithDefaultOffset sets the pagination CheckPayment and ListPayments use for
+// zero Offset fields. A field set on the request wins, then this default, then
+// DefaultPageNumber and DefaultPageLimit.
+func WithDefaultOffset(o Offset) Option {
+	return func(c *Client) {
+		c.defaultOffset = &o
+	}
+}
+
+// WithRoundTripper wraps the client's transport with wrap, for example to add
+// logging or tracing, keeping the configured timeout and transport
+// underneath. A nil Transport is taken to be http.DefaultTransport. The
+// http.Client passed to NewClientWithHTTPClient is copied, not modified.
+// Later WithRoundTripper options wrap earlier ones.
+func WithRoundTripper(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *Client) {
+		base := c.http.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		hc := *c.http
+		hc.Transport = wrap(base)
+		c.http = &hc
+	}
+}
+
+// WithDialTimeout bounds how long connecting to QPay may take, including DNS
+// lookup, separately from the http.Client Timeout, which covers the whole
+// request. With a 2s dial timeout and the default 30s timeout, an unreachable
+// host fails after 2s while a slow response still has 30s; a dial timeout
+// longer than the overall timeout has no effect.
+//
+// It clones the client's *http.Transport (http.DefaultTransport if none is
+// set) rather than modifying it. A custom RoundTripper of another type is
+// left unchanged, so apply WithDialTimeout before WithRoundTripper.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		base := c.http.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		t, ok := base.(*http.Transport)
+		if !ok {
+			return
+		}
+		t = t.Clone()
+		t.DialContext = (&net.Dialer{Timeout: d, KeepAlive: 30 * time.Second}).DialContext
+
+		hc := *c.http
+		hc.Transport = t
+		c.http = &hc
+	}
+}
+
+// WithClock replaces time.Now for token expiry checks and cache TTLs, so tests
+// can move time forward deterministically.
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.clock = now
+	}
+}
+
+// now returns the current time from the configured clock.
+func (c *Client) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// send performs req, retrying as configured by WithRetryPolicy, buffers the
+// response body and runs the response hooks. The returned response's Body is a
+// re-readable copy of the returned bytes. The context deadline bounds all
+// attempts together: a backoff that would outlast it is skipped and the last
+// attempt's result returned at once.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, body, _, err := c.sendDecode(req, nil)
+	return resp, body, err
+}
+
+// streamDecoder is implemented by results that can decode themselves
+// incrementally. json.Decoder.Decode buffers a whole value before decoding
+// it, so streaming only saves memory for results that walk their tokens,
+// like PaymentListResponse does with its rows.
+type streamDecoder interface {
+	decodeStream(dec *json.Decoder) error
+}
+
+// sendDecode is send that, given a streamDecoder result, decodes a 2xx JSON
+// response straight from the connection instead of buffering it, so large
+// listings aren't held in memory twice. It reports whether it did; the
+// returned body is then nil. Error responses, HTML pages, other results and
+// clients with response hooks are buffered as by send.
+func (c *Client) sendDecode(req *http.Request, result interface{}) (*http.Response, []byte, bool, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, nil, false, fmt.Errorf("rate limit: %w", err)
+			}
+		}
+		resp, body, decoded, err := c.sendOnce(req, result)
+		if decoded {
+			return resp, nil, true, err
+		}
+
+		canResend := req.Body == nil || req.GetBody != nil
+		if attempt >= c.retry.MaxAttempts || !canResend || ctx.Err() != nil || !c.retry.shouldRetry(resp, body, err, attempt) {
+			return resp, body, false, err
+		}
+		delay := c.retry.backoff(attempt, c.rand)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return resp, body, false, err
+		}
+		if sleep(ctx, delay) != nil {
+			return resp, body, false, err
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, nil, false, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+		}
+	}
+}
+
+// maxDrainBytes bounds how much of an unread response body drainAndClose
+// discards. Larger remainders are cheaper to drop with the connection.
+const maxDrainBytes = 64 << 10
+
+// drainAndClose discards up to maxDrainBytes of what is left of body before
+// closing it, so the transport can reuse the connection after a failed read.
+// If the request's context was canceled the read fails at once and the
+// transport discards the connection instead.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// htmlSniffLen is how much of a streamed response sendOnce inspects for an
+// HTML page before decoding it as JSON.
+const htmlSniffLen = 512
+
+// sendOnce performs a single attempt of req, decoding into result as
+// described on sendDecode.
+func (c *Client) sendOnce(req *http.Request, result interface{}) (*http.Response, []byte, bool, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("request failed: %w", err)
+	}
+	defer drainAndClose(resp.Body)
+
+	var r io.Reader = resp.Body
+	sd, stream := result.(streamDecoder)
+	if stream && len(c.responseHooks) == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		br := bufio.NewReader(resp.Body)
+		head, _ := br.Peek(htmlSniffLen)
+		if !isHTML(resp.Header.Get("Content-Type"), head) {
+			err := sd.decodeStream(json.NewDecoder(br))
+			if err == io.EOF {
+				err = nil // empty body
+			}
+			if err != nil {
+				err = newDecodeError(resp.StatusCode, result, nil, err)
+			}
+			return resp, nil, true, err
+		}
+		r = br
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	for _, hook := range c.responseHooks {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		hook(req, resp)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, body, false, nil
+}
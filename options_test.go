@@ -0,0 +1,408 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient_AppliesOptions(t *testing.T) {
+	hook := func(req *http.Request, resp *http.Response) {}
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn"}, WithResponseHook(hook), WithResponseHook(hook))
+
+	if len(client.responseHooks) != 2 {
+		t.Errorf("expected 2 response hooks, got %d", len(client.responseHooks))
+	}
+}
+
+func TestWithResponseHook_SeesEveryResponse(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		json.NewEncoder(w).Encode(map[string]string{"invoice_id": "inv-1"})
+	})
+	defer server.Close()
+
+	var paths, bodies []string
+	var remaining string
+	WithResponseHook(func(req *http.Request, resp *http.Response) {
+		paths = append(paths, req.URL.Path)
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Errorf("reading hook body: %v", err)
+		}
+		bodies = append(bodies, string(data))
+		if v := resp.Header.Get("X-RateLimit-Remaining"); v != "" {
+			remaining = v
+		}
+	})(client)
+
+	var result InvoiceResponse
+	if err := client.doRequest(context.Background(), testOp, "GET", "/v2/invoice/inv-1", nil, &result); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+
+	if result.InvoiceID != "inv-1" {
+		t.Errorf("expected decoded invoice_id 'inv-1', got %q", result.InvoiceID)
+	}
+	if len(paths) != 2 || paths[0] != "/v2/auth/token" || paths[1] != "/v2/invoice/inv-1" {
+		t.Errorf("expected hook for token and invoice calls, got %v", paths)
+	}
+	if len(bodies) == 2 && bodies[1] == "" {
+		t.Error("expected hook to read the response body")
+	}
+	if remaining != "41" {
+		t.Errorf("expected rate-limit header '41', got %q", remaining)
+	}
+}
+
+func TestWithResponseHook_BodyReadableByEachHook(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   ErrInvoiceNotFound,
+			"message": "Invoice not found",
+		})
+	})
+	defer server.Close()
+
+	var reads []int
+	hook := func(req *http.Request, resp *http.Response) {
+		data, _ := io.ReadAll(resp.Body)
+		reads = append(reads, len(data))
+	}
+	WithResponseHook(hook)(client)
+	WithResponseHook(hook)(client)
+
+	err := client.doRequest(context.Background(), testOp, "GET", "/v2/invoice/x", nil, nil)
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.Code != ErrInvoiceNotFound {
+		t.Fatalf("expected INVOICE_NOTFOUND error, got %v", err)
+	}
+	// Token response plus error response, each seen by both hooks.
+	if len(reads) != 4 {
+		t.Fatalf("expected 4 hook calls, got %d", len(reads))
+	}
+	if reads[2] == 0 || reads[2] != reads[3] {
+		t.Errorf("expected both hooks to read the full error body, got %v", reads)
+	}
+}
+
+type trackingBody struct {
+	r      io.Reader
+	read   int
+	closed bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDrainAndClose(t *testing.T) {
+	small := &trackingBody{r: strings.NewReader("leftover")}
+	drainAndClose(small)
+	if !small.closed || small.read != len("leftover") {
+		t.Errorf("expected small body drained and closed, read %d closed %v", small.read, small.closed)
+	}
+
+	large := &trackingBody{r: strings.NewReader(strings.Repeat("x", 2*maxDrainBytes))}
+	drainAndClose(large)
+	if !large.closed || large.read != maxDrainBytes {
+		t.Errorf("expected drain capped at %d bytes, read %d closed %v", maxDrainBytes, large.read, large.closed)
+	}
+}
+
+type failingBodyTransport struct {
+	body *trackingBody
+}
+
+func (t *failingBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: t.body, Header: http.Header{}}, nil
+}
+
+type errAfterReader struct {
+	data string
+	done bool
+}
+
+func (r *errAfterReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, errors.New("connection reset")
+	}
+	r.done = true
+	return copy(p, r.data), nil
+}
+
+func TestSend_ClosesBodyOnReadError(t *testing.T) {
+	body := &trackingBody{r: &errAfterReader{data: `{"partial":`}}
+	client := NewClientWithHTTPClient(&Config{BaseURL: "http://qpay.test"}, &http.Client{Transport: &failingBodyTransport{body: body}})
+
+	req, _ := http.NewRequest("GET", "http://qpay.test/v2/payment/p1", nil)
+	_, _, err := client.send(req)
+	if err == nil || !strings.Contains(err.Error(), "failed to read response body") {
+		t.Fatalf("expected read error, got %v", err)
+	}
+	if !body.closed {
+		t.Error("expected body to be closed after a read error")
+	}
+}
+
+func TestSendDecode_StreamsSuccess(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentListResponse{Count: 2, Rows: []PaymentListItem{{PaymentID: "p1"}, {PaymentID: "p2"}}})
+	})
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/v2/payment/list", nil)
+	var result PaymentListResponse
+	_, body, decoded, err := client.sendDecode(req, &result)
+	if err != nil {
+		t.Fatalf("sendDecode failed: %v", err)
+	}
+	if !decoded || body != nil {
+		t.Errorf("expected a streamed decode, got decoded=%v body=%q", decoded, body)
+	}
+	if result.Count != 2 || len(result.Rows) != 2 || result.Rows[1].PaymentID != "p2" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestSendDecode_BuffersForHooksAndErrors(t *testing.T) {
+	status := http.StatusOK
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"count":1}`))
+	})
+	defer server.Close()
+
+	var result PaymentListResponse
+	status = http.StatusBadRequest
+	req, _ := http.NewRequest("GET", server.URL+"/v2/payment/list", nil)
+	if _, body, decoded, _ := client.sendDecode(req, &result); decoded || string(body) != `{"count":1}` {
+		t.Errorf("expected error response to be buffered, got decoded=%v body=%q", decoded, body)
+	}
+
+	status = http.StatusOK
+	WithResponseHook(func(*http.Request, *http.Response) {})(client)
+	req, _ = http.NewRequest("GET", server.URL+"/v2/payment/list", nil)
+	if _, body, decoded, _ := client.sendDecode(req, &result); decoded || string(body) != `{"count":1}` {
+		t.Errorf("expected buffering with a hook, got decoded=%v body=%q", decoded, body)
+	}
+}
+
+func TestDoRequest_StreamedEdgeCases(t *testing.T) {
+	var body string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	})
+	defer server.Close()
+
+	var result PaymentListResponse
+	body = ""
+	if err := client.doRequest(context.Background(), testOp, "GET", "/v2/payment/p1", nil, &result); err != nil {
+		t.Errorf("expected empty body to succeed, got %v", err)
+	}
+
+	body = `{"count":2,"rows":[{"payment_id":"p1"}`
+	err := client.doRequest(context.Background(), testOp, "GET", "/v2/payment/p1", nil, &result)
+	if err == nil || !strings.Contains(err.Error(), "failed to unmarshal response") {
+		t.Errorf("expected unmarshal error, got %v", err)
+	}
+
+	body = "\n  <html><body>Maintenance</body></html>"
+	err = client.doRequest(context.Background(), testOp, "GET", "/v2/payment/p1", nil, &result)
+	if qErr, ok := IsQPayError(err); !ok || qErr.Code != ErrUpstreamUnavailable {
+		t.Errorf("expected UPSTREAM_UNAVAILABLE, got %v", err)
+	}
+}
+
+// BenchmarkListPayments_Large compares memory for a 20k-row listing decoded
+// from the stream against the buffered path a response hook forces.
+func BenchmarkListPayments_Large(b *testing.B) {
+	rows := make([]PaymentListItem, 20000)
+	for i := range rows {
+		rows[i] = PaymentListItem{PaymentID: fmt.Sprintf("pay-%d", i), PaymentStatus: PaymentStatusPaid, PaymentAmount: "1000.00"}
+	}
+	payload, _ := json.Marshal(PaymentListResponse{Count: len(rows), Rows: rows})
+	client, server := newTestClient(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	})
+	defer server.Close()
+	buffered, bufferedServer := newTestClient(b, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	})
+	defer bufferedServer.Close()
+	WithResponseHook(func(*http.Request, *http.Response) {})(buffered)
+	req := &PaymentListRequest{ObjectType: "MERCHANT", ObjectID: "m", StartDate: "2024-01-01", EndDate: "2024-01-31"}
+
+	run := func(b *testing.B, c *Client) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.ListPayments(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("streamed", func(b *testing.B) { run(b, client) })
+	b.Run("buffered", func(b *testing.B) { run(b, buffered) })
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWithRoundTripper_WrapsTransport(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	original := client.http
+	timeout := 7 * time.Second
+	original.Timeout = timeout
+
+	var order []string
+	wrapper := func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" "+req.URL.Path)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	WithRoundTripper(wrapper("inner"))(client)
+	WithRoundTripper(wrapper("outer"))(client)
+
+	if _, err := client.GetPayment(context.Background(), "PAY-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"outer /v2/auth/token", "inner /v2/auth/token",
+		"outer /v2/payment/PAY-1", "inner /v2/payment/PAY-1",
+	}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, order)
+	}
+	if client.http.Timeout != timeout {
+		t.Errorf("expected timeout %v to be kept, got %v", timeout, client.http.Timeout)
+	}
+	if original.Transport == client.http.Transport {
+		t.Error("expected the caller's http.Client to be left unmodified")
+	}
+}
+
+func TestWithRoundTripper_DefaultTransport(t *testing.T) {
+	client := NewClient(&Config{})
+	var got http.RoundTripper
+	WithRoundTripper(func(next http.RoundTripper) http.RoundTripper {
+		got = next
+		return next
+	})(client)
+
+	if got != http.DefaultTransport {
+		t.Errorf("expected http.DefaultTransport to be wrapped, got %T", got)
+	}
+	if client.http.Timeout != 30*time.Second {
+		t.Errorf("expected the default timeout to be kept, got %v", client.http.Timeout)
+	}
+}
+
+func TestWithDialTimeout(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	original := client.http
+	originalTransport := original.Transport.(*http.Transport)
+
+	WithDialTimeout(2 * time.Second)(client)
+
+	transport, ok := client.http.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.http.Transport)
+	}
+	if transport == originalTransport || original.Transport != originalTransport {
+		t.Error("expected the transport to be cloned, not modified")
+	}
+	if transport.DialContext == nil {
+		t.Fatal("expected DialContext to be set")
+	}
+	if _, err := client.GetPayment(context.Background(), "PAY-1"); err != nil {
+		t.Fatalf("request through the new transport failed: %v", err)
+	}
+}
+
+func TestWithDialTimeout_DefaultAndCustomTransports(t *testing.T) {
+	client := NewClient(&Config{})
+	WithDialTimeout(time.Second)(client)
+	if client.http.Transport == nil || client.http.Transport == http.DefaultTransport {
+		t.Error("expected a clone of http.DefaultTransport")
+	}
+	if http.DefaultTransport.(*http.Transport).DialContext == nil {
+		t.Fatal("unexpected nil DialContext on http.DefaultTransport")
+	}
+	if client.http.Timeout != 30*time.Second {
+		t.Errorf("expected the default timeout to be kept, got %v", client.http.Timeout)
+	}
+
+	custom := roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, errors.New("unused") })
+	client = NewClientWithHTTPClient(&Config{}, &http.Client{Transport: custom})
+	WithDialTimeout(time.Second)(client)
+	if _, ok := client.http.Transport.(roundTripperFunc); !ok {
+		t.Errorf("expected a custom transport to be left unchanged, got %T", client.http.Transport)
+	}
+}
+
+func TestWithURLRewriter(t *testing.T) {
+	var paths []string
+	var target *url.URL
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Host != target.Host {
+			t.Errorf("expected the Host header to follow the rewrite, got %q", r.Host)
+		}
+		if r.URL.Path == "/mn/v2/auth/token" {
+			json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", ExpiresIn: 3600})
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer access" {
+			t.Errorf("expected the bearer token, got %q", got)
+		}
+		var req PaymentCheckRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ObjectID != "inv-1" {
+			t.Errorf("expected the request body, got %+v", req)
+		}
+		json.NewEncoder(w).Encode(PaymentCheckResponse{})
+	}))
+	defer mirror.Close()
+	target, _ = url.Parse(mirror.URL)
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: "https://merchant.qpay.mn", Username: "u", Password: "p"}, mirror.Client(),
+		WithURLRewriter(func(u *url.URL) {
+			u.Scheme = target.Scheme
+			u.Host = target.Host
+			u.Path = "/mn" + u.Path
+		}))
+
+	if _, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"}); err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if want := "[/mn/v2/auth/token /mn/v2/payment/check]"; fmt.Sprint(paths) != want {
+		t.Errorf("expected %s, got %v", want, paths)
+	}
+}
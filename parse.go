@@ -0,0 +1,46 @@
+package qpay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the timestamp formats seen in QPay responses.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseAmount parses a decimal amount QPay returns as a string. field names
+// the JSON field in error messages.
+func parseAmount(field, s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("qpay: invalid %s %q: %w", field, s, err)
+	}
+	return v, nil
+}
+
+// parseOptionalAmount is like parseAmount but treats an empty value as zero.
+func parseOptionalAmount(field, s string) (float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return parseAmount(field, s)
+}
+
+// parseTime parses a timestamp QPay returns as a string. Values without a
+// zone are taken as UTC.
+func parseTime(field, s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("qpay: invalid %s %q", field, s)
+}
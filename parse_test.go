@@ -0,0 +1,54 @@
+package qpay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 11, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-01-11T09:30:00Z", want},
+		{"2024-01-11T09:30:00.000Z", want},
+		{"2024-01-11T17:30:00+08:00", want},
+		{"2024-01-11T09:30:00", want},
+		{"2024-01-11 09:30:00", want},
+		{" 2024-01-11 09:30:00 ", want},
+		{"2024-01-11", time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTime("payment_date", tt.input)
+		if err != nil {
+			t.Errorf("parseTime(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("parseTime(%q): expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestParseTime_Invalid(t *testing.T) {
+	for _, input := range []string{"", "yesterday", "11/01/2024"} {
+		if _, err := parseTime("payment_date", input); err == nil {
+			t.Errorf("parseTime(%q): expected error, got nil", input)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	if v, err := parseAmount("amount", " 1500.50 "); err != nil || v != 1500.5 {
+		t.Errorf("expected 1500.5, got %v, %v", v, err)
+	}
+	if _, err := parseAmount("amount", ""); err == nil {
+		t.Error("expected error for empty amount")
+	}
+	if v, err := parseOptionalAmount("fee", ""); err != nil || v != 0 {
+		t.Errorf("expected 0 for empty optional amount, got %v, %v", v, err)
+	}
+}
@@ -1,45 +1,171 @@
 package qpay
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Pagination defaults applied by CheckPayment and ListPayments to zero Offset fields.
+const (
+	DefaultPageNumber = 1
+	DefaultPageLimit  = 100
+)
 
 // GetPayment retrieves payment details by payment ID.
 // GET /v2/payment/{id}
 func (c *Client) GetPayment(ctx context.Context, paymentID string) (*PaymentDetail, error) {
 	var resp PaymentDetail
-	if err := c.doRequest(ctx, "GET", "/v2/payment/"+paymentID, nil, &resp); err != nil {
+	if err := c.doRequest(ctx, OpPaymentGet, "GET", c.apiPath("/payment/"+paymentID), nil, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
 // CheckPayment checks if a payment has been made for an invoice.
+// A non-nil Offset is normalized as described on Offset. A nil Offset is sent
+// as nil unless the client has a WithDefaultOffset default.
 // POST /v2/payment/check
 func (c *Client) CheckPayment(ctx context.Context, req *PaymentCheckRequest) (*PaymentCheckResponse, error) {
+	if req.Offset != nil || c.defaultOffset != nil {
+		var o Offset
+		if req.Offset != nil {
+			o = *req.Offset
+		}
+		offset, err := c.offset(o)
+		if err != nil {
+			return nil, err
+		}
+		r := *req
+		r.Offset = &offset
+		req = &r
+	}
+
 	var resp PaymentCheckResponse
-	if err := c.doRequest(ctx, "POST", "/v2/payment/check", req, &resp); err != nil {
+	if err := c.doRequest(ctx, OpPaymentCheck, "POST", c.apiPath("/payment/check"), req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// GetLatestPayment returns the most recent PAID payment for an object, using
+// CheckPayment's first page of DefaultPageLimit rows; WithDefaultOffset does
+// not change which page it reads. Rows are ordered by
+// PaymentDate; rows whose date is missing or unparseable are used only when
+// no paid row has a date. It returns ErrNoPaidPayment when nothing is paid.
+// POST /v2/payment/check
+func (c *Client) GetLatestPayment(ctx context.Context, objectType, objectID string) (*PaymentCheckRow, error) {
+	resp, err := c.CheckPayment(ctx, &PaymentCheckRequest{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		Offset:     &Offset{PageNumber: DefaultPageNumber, PageLimit: DefaultPageLimit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if row := latestPaidRow(resp.Rows); row != nil {
+		return row, nil
+	}
+	return nil, ErrNoPaidPayment
+}
+
+// latestPaidRow returns the most recent PAID row as described on
+// GetLatestPayment, or nil if no row is paid.
+func latestPaidRow(rows []PaymentCheckRow) *PaymentCheckRow {
+	var latest, undated *PaymentCheckRow
+	var latestAt time.Time
+	for i := range rows {
+		row := &rows[i]
+		if row.PaymentStatus != PaymentStatusPaid {
+			continue
+		}
+		at, err := parseTime("payment_date", row.PaymentDate)
+		if err != nil {
+			if undated == nil {
+				undated = row
+			}
+			continue
+		}
+		if latest == nil || at.After(latestAt) {
+			latest, latestAt = row, at
+		}
+	}
+	if latest != nil {
+		return latest
+	}
+	return undated
+}
+
 // ListPayments returns a list of payments matching the given criteria.
+// The Offset is normalized as described on Offset. A successful response is
+// decoded row by row from the connection rather than buffered first, unless
+// the client has response hooks, which need the whole body.
 // POST /v2/payment/list
 func (c *Client) ListPayments(ctx context.Context, req *PaymentListRequest) (*PaymentListResponse, error) {
+	offset, err := c.offset(req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Offset = offset
+	req = &r
+
 	var resp PaymentListResponse
-	if err := c.doRequest(ctx, "POST", "/v2/payment/list", req, &resp); err != nil {
+	if err := c.doRequest(ctx, OpPaymentList, "POST", c.apiPath("/payment/list"), req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
 // CancelPayment cancels a payment (card transactions only).
+// A nil req sends an empty JSON object.
 // DELETE /v2/payment/cancel/{id}
 func (c *Client) CancelPayment(ctx context.Context, paymentID string, req *PaymentCancelRequest) error {
-	return c.doRequest(ctx, "DELETE", "/v2/payment/cancel/"+paymentID, req, nil)
+	if req == nil {
+		req = &PaymentCancelRequest{}
+	}
+	return c.doRequest(ctx, OpPaymentCancel, "DELETE", c.apiPath("/payment/cancel/"+paymentID), req, nil)
 }
 
 // RefundPayment refunds a payment (card transactions only).
+// A nil req sends an empty JSON object.
 // DELETE /v2/payment/refund/{id}
 func (c *Client) RefundPayment(ctx context.Context, paymentID string, req *PaymentRefundRequest) error {
-	return c.doRequest(ctx, "DELETE", "/v2/payment/refund/"+paymentID, req, nil)
+	if req == nil {
+		req = &PaymentRefundRequest{}
+	}
+	return c.doRequest(ctx, OpPaymentRefund, "DELETE", c.apiPath("/payment/refund/"+paymentID), req, nil)
+}
+
+// offset fills zero fields of o from the WithDefaultOffset default, then
+// normalizes the result.
+func (c *Client) offset(o Offset) (Offset, error) {
+	if d := c.defaultOffset; d != nil {
+		if o.PageNumber == 0 {
+			o.PageNumber = d.PageNumber
+		}
+		if o.PageLimit == 0 {
+			o.PageLimit = d.PageLimit
+		}
+	}
+	return o.normalize()
+}
+
+// normalize replaces zero fields with DefaultPageNumber and DefaultPageLimit
+// and rejects negative values.
+func (o Offset) normalize() (Offset, error) {
+	if o.PageNumber < 0 {
+		return o, &ValidationError{Field: "offset.page_number", Message: fmt.Sprintf("must not be negative, got %d", o.PageNumber)}
+	}
+	if o.PageLimit < 0 {
+		return o, &ValidationError{Field: "offset.page_limit", Message: fmt.Sprintf("must not be negative, got %d", o.PageLimit)}
+	}
+	if o.PageNumber == 0 {
+		o.PageNumber = DefaultPageNumber
+	}
+	if o.PageLimit == 0 {
+		o.PageLimit = DefaultPageLimit
+	}
+	return o, nil
 }
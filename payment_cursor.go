@@ -0,0 +1,144 @@
+package qpay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// PaymentCursor is a resumable position in a ListPayments listing. It is
+// plain data: persist it, for example as JSON, and pass it back to NextPage
+// after a restart to continue where the listing stopped.
+type PaymentCursor struct {
+	ObjectType string `json:"object_type"`
+	ObjectID   string `json:"object_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	PageNumber int    `json:"page_number"`
+	PageLimit  int    `json:"page_limit"`
+
+	// ParamsHash fingerprints the filter fields above, so a cursor edited or
+	// mixed up with another listing's is rejected. See Matches.
+	ParamsHash string `json:"params_hash"`
+}
+
+// NewPaymentCursor returns a cursor at the page req.Offset selects, for the
+// filters in req. Zero Offset fields are filled in by NextPage as in
+// ListPayments.
+func NewPaymentCursor(req *PaymentListRequest) *PaymentCursor {
+	return &PaymentCursor{
+		ObjectType: req.ObjectType,
+		ObjectID:   req.ObjectID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		PageNumber: req.Offset.PageNumber,
+		PageLimit:  req.Offset.PageLimit,
+		ParamsHash: paymentParamsHash(req.ObjectType, req.ObjectID, req.StartDate, req.EndDate),
+	}
+}
+
+// Matches reports whether the cursor was created for req's filters, so a
+// resumed job can tell a persisted cursor belongs to the listing it is about
+// to continue. Offsets are not compared.
+func (cur *PaymentCursor) Matches(req *PaymentListRequest) bool {
+	return cur.ParamsHash == paymentParamsHash(req.ObjectType, req.ObjectID, req.StartDate, req.EndDate)
+}
+
+// NextPage fetches the page at cursor and returns it with the cursor for the
+// following page. The next cursor is nil once the listing is exhausted, when
+// the page was short or empty. Count is not consulted, since QPay may leave it
+// out or under-report it; a listing that ends on a full page costs one more
+// request, for an empty page. A cursor whose ParamsHash does not match its
+// filters returns a *ValidationError.
+func (c *Client) NextPage(ctx context.Context, cursor *PaymentCursor) (*PaymentListResponse, *PaymentCursor, error) {
+	if cursor.ParamsHash != paymentParamsHash(cursor.ObjectType, cursor.ObjectID, cursor.StartDate, cursor.EndDate) {
+		return nil, nil, &ValidationError{Field: "cursor", Message: "params_hash does not match the cursor's filters"}
+	}
+	offset, err := c.offset(Offset{PageNumber: cursor.PageNumber, PageLimit: cursor.PageLimit})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, err := c.ListPayments(ctx, &PaymentListRequest{
+		ObjectType: cursor.ObjectType,
+		ObjectID:   cursor.ObjectID,
+		StartDate:  cursor.StartDate,
+		EndDate:    cursor.EndDate,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(page.Rows) < offset.PageLimit {
+		return page, nil, nil
+	}
+	next := *cursor
+	next.PageNumber = offset.PageNumber + 1
+	next.PageLimit = offset.PageLimit
+	return page, &next, nil
+}
+
+// Default limits for ListAllPayments. At DefaultPageLimit rows per page,
+// DefaultMaxListPages pages hold DefaultMaxListItems rows.
+const (
+	DefaultMaxListPages = 1000
+	DefaultMaxListItems = 100_000
+)
+
+// ErrListTruncated is returned by ListAllPayments, with the rows fetched so
+// far, when the listing had more rows than its MaxPages or MaxItems allow.
+var ErrListTruncated = errors.New("qpay: payment listing truncated")
+
+// ListAllOptions bounds ListAllPayments. Zero fields use DefaultMaxListPages
+// and DefaultMaxListItems; a negative field disables that limit.
+type ListAllOptions struct {
+	MaxPages int
+	MaxItems int
+}
+
+// ListAllPayments fetches every page of the listing req selects, starting at
+// req.Offset, and returns all rows. Paging stops as NextPage does, and also
+// at the limits in opts (nil for the defaults), which guard against a
+// listing that never returns a short page pulling rows without end: if rows
+// remain past a limit, the rows gathered so far are returned with
+// ErrListTruncated, cut to MaxItems. An error partway returns the rows from
+// the pages before it.
+func (c *Client) ListAllPayments(ctx context.Context, req *PaymentListRequest, opts *ListAllOptions) ([]PaymentListItem, error) {
+	maxPages, maxItems := DefaultMaxListPages, DefaultMaxListItems
+	if opts != nil {
+		if opts.MaxPages != 0 {
+			maxPages = opts.MaxPages
+		}
+		if opts.MaxItems != 0 {
+			maxItems = opts.MaxItems
+		}
+	}
+
+	var rows []PaymentListItem
+	cursor := NewPaymentCursor(req)
+	for pages := 0; cursor != nil; pages++ {
+		if maxPages > 0 && pages == maxPages {
+			return rows, ErrListTruncated
+		}
+		page, next, err := c.NextPage(ctx, cursor)
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, page.Rows...)
+		if maxItems > 0 && len(rows) >= maxItems && (len(rows) > maxItems || next != nil) {
+			return rows[:maxItems], ErrListTruncated
+		}
+		cursor = next
+	}
+	return rows, nil
+}
+
+// paymentParamsHash fingerprints the filters of a payment listing.
+func paymentParamsHash(objectType, objectID, startDate, endDate string) string {
+	data, _ := json.Marshal([]string{objectType, objectID, startDate, endDate})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,243 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func newPagingServer(t *testing.T, total int, requests *int) (*Client, func()) {
+	t.Helper()
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		var req PaymentListRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.ObjectID != "MERCHANT-1" || req.StartDate != "2025-01-01" {
+			t.Errorf("unexpected filters: %+v", req)
+		}
+		resp := PaymentListResponse{Count: total}
+		first := (req.Offset.PageNumber - 1) * req.Offset.PageLimit
+		for i := first; i < first+req.Offset.PageLimit && i < total; i++ {
+			resp.Rows = append(resp.Rows, PaymentListItem{PaymentID: fmt.Sprintf("PAY-%d", i+1)})
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	return client, server.Close
+}
+
+func TestNextPage_ResumesFromPersistedCursor(t *testing.T) {
+	var requests int
+	client, closeServer := newPagingServer(t, 5, &requests)
+	defer closeServer()
+
+	req := &PaymentListRequest{
+		ObjectType: "MERCHANT",
+		ObjectID:   "MERCHANT-1",
+		StartDate:  "2025-01-01",
+		EndDate:    "2025-01-31",
+		Offset:     Offset{PageLimit: 2},
+	}
+	cursor := NewPaymentCursor(req)
+
+	var ids []string
+	for cursor != nil {
+		page, next, err := client.NextPage(context.Background(), cursor)
+		if err != nil {
+			t.Fatalf("NextPage failed: %v", err)
+		}
+		for _, row := range page.Rows {
+			ids = append(ids, row.PaymentID)
+		}
+		if next == nil {
+			break
+		}
+
+		// Simulate a restart: persist the cursor and load it back.
+		data, err := json.Marshal(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor = &PaymentCursor{}
+		if err := json.Unmarshal(data, cursor); err != nil {
+			t.Fatal(err)
+		}
+		if !cursor.Matches(req) {
+			t.Fatal("expected the restored cursor to match the request")
+		}
+	}
+
+	if fmt.Sprint(ids) != "[PAY-1 PAY-2 PAY-3 PAY-4 PAY-5]" {
+		t.Errorf("unexpected payments: %v", ids)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestNextPage_FullLastPage(t *testing.T) {
+	var requests int
+	client, closeServer := newPagingServer(t, 4, &requests)
+	defer closeServer()
+
+	cursor := NewPaymentCursor(&PaymentListRequest{ObjectID: "MERCHANT-1", StartDate: "2025-01-01", Offset: Offset{PageNumber: 2, PageLimit: 2}})
+	page, next, err := client.NextPage(context.Background(), cursor)
+	if err != nil {
+		t.Fatalf("NextPage failed: %v", err)
+	}
+	if len(page.Rows) != 2 || next == nil {
+		t.Fatalf("expected a full page and a next cursor, got %d rows and next %+v", len(page.Rows), next)
+	}
+	page, next, err = client.NextPage(context.Background(), next)
+	if err != nil {
+		t.Fatalf("NextPage failed: %v", err)
+	}
+	if len(page.Rows) != 0 || next != nil {
+		t.Errorf("expected an empty final page, got %d rows and next %+v", len(page.Rows), next)
+	}
+}
+
+func TestListAllPayments_IgnoresCount(t *testing.T) {
+	for name, count := range map[string]int{"missing": 0, "under-reported": 2} {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+			client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				var req PaymentListRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				requests++
+				resp := PaymentListResponse{Count: count}
+				if req.Offset.PageNumber < 3 {
+					resp.Rows = make([]PaymentListItem, req.Offset.PageLimit)
+				} else {
+					resp.Rows = make([]PaymentListItem, 1)
+				}
+				json.NewEncoder(w).Encode(resp)
+			})
+			defer server.Close()
+
+			rows, err := client.ListAllPayments(context.Background(), &PaymentListRequest{ObjectID: "MERCHANT-1", Offset: Offset{PageLimit: 2}}, nil)
+			if err != nil {
+				t.Fatalf("ListAllPayments failed: %v", err)
+			}
+			if len(rows) != 5 || requests != 3 {
+				t.Errorf("expected 5 rows in 3 requests, got %d in %d", len(rows), requests)
+			}
+		})
+	}
+}
+
+func TestNextPage_RejectsMismatchedCursor(t *testing.T) {
+	var requests int
+	client, closeServer := newPagingServer(t, 5, &requests)
+	defer closeServer()
+
+	req := &PaymentListRequest{ObjectID: "MERCHANT-1", StartDate: "2025-01-01"}
+	cursor := NewPaymentCursor(req)
+	cursor.ObjectID = "MERCHANT-2"
+
+	_, _, err := client.NextPage(context.Background(), cursor)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "cursor" {
+		t.Fatalf("expected a cursor ValidationError, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no request, got %d", requests)
+	}
+	if cursor.Matches(&PaymentListRequest{ObjectID: "MERCHANT-2", StartDate: "2025-01-01"}) {
+		t.Error("expected Matches to be false for another listing")
+	}
+}
+
+func TestListAllPayments(t *testing.T) {
+	var requests int
+	client, closeServer := newPagingServer(t, 5, &requests)
+	defer closeServer()
+
+	rows, err := client.ListAllPayments(context.Background(), &PaymentListRequest{
+		ObjectID: "MERCHANT-1", StartDate: "2025-01-01", Offset: Offset{PageLimit: 2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("ListAllPayments failed: %v", err)
+	}
+	if len(rows) != 5 || rows[4].PaymentID != "PAY-5" || requests != 3 {
+		t.Errorf("expected 5 rows in 3 requests, got %d rows in %d", len(rows), requests)
+	}
+}
+
+func TestListAllPayments_Limits(t *testing.T) {
+	req := &PaymentListRequest{ObjectID: "MERCHANT-1", StartDate: "2025-01-01", Offset: Offset{PageLimit: 2}}
+	tests := []struct {
+		name      string
+		opts      ListAllOptions
+		wantRows  int
+		wantPages int
+		truncated bool
+	}{
+		{"max pages", ListAllOptions{MaxPages: 2}, 4, 2, true},
+		{"max items mid-page", ListAllOptions{MaxItems: 3}, 3, 2, true},
+		{"max items at the end", ListAllOptions{MaxItems: 5}, 5, 3, false},
+		{"max pages at the end", ListAllOptions{MaxPages: 3}, 5, 3, false},
+		{"unlimited", ListAllOptions{MaxPages: -1, MaxItems: -1}, 5, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			client, closeServer := newPagingServer(t, 5, &requests)
+			defer closeServer()
+
+			opts := tt.opts
+			rows, err := client.ListAllPayments(context.Background(), req, &opts)
+			if errors.Is(err, ErrListTruncated) != tt.truncated {
+				t.Errorf("expected truncated %v, got %v", tt.truncated, err)
+			}
+			if len(rows) != tt.wantRows || requests != tt.wantPages {
+				t.Errorf("expected %d rows in %d requests, got %d in %d", tt.wantRows, tt.wantPages, len(rows), requests)
+			}
+		})
+	}
+}
+
+func TestListAllPayments_MisreportedCount(t *testing.T) {
+	var requests int
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(PaymentListResponse{
+			Count: 1 << 30,
+			Rows:  make([]PaymentListItem, DefaultPageLimit),
+		})
+	})
+	defer server.Close()
+
+	rows, err := client.ListAllPayments(context.Background(), &PaymentListRequest{ObjectID: "MERCHANT-1"}, &ListAllOptions{MaxPages: -1, MaxItems: 1000})
+	if !errors.Is(err, ErrListTruncated) {
+		t.Fatalf("expected ErrListTruncated, got %v", err)
+	}
+	if len(rows) != 1000 || requests != 1000/DefaultPageLimit {
+		t.Errorf("expected 1000 rows, got %d in %d requests", len(rows), requests)
+	}
+}
+
+func TestListAllPayments_ErrorKeepsRows(t *testing.T) {
+	var requests int
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(PaymentListResponse{Count: 4, Rows: make([]PaymentListItem, 2)})
+	})
+	defer server.Close()
+
+	rows, err := client.ListAllPayments(context.Background(), &PaymentListRequest{ObjectID: "MERCHANT-1", Offset: Offset{PageLimit: 2}}, nil)
+	if err == nil || errors.Is(err, ErrListTruncated) {
+		t.Fatalf("expected the request error, got %v", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("expected the first page's 2 rows, got %d", len(rows))
+	}
+}
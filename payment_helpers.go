@@ -0,0 +1,190 @@
+package qpay
+
+import "fmt"
+
+// Payment methods returned by PaymentMethod.
+const (
+	PaymentMethodCard   = "CARD"
+	PaymentMethodP2P    = "P2P"
+	PaymentMethodWallet = "WALLET"
+)
+
+// Payment statuses QPay reports in PaymentStatus.
+const (
+	PaymentStatusNew      = "NEW"
+	PaymentStatusFailed   = "FAILED"
+	PaymentStatusPaid     = "PAID"
+	PaymentStatusRefunded = "REFUNDED"
+)
+
+// paymentMethod classifies a payment from its transaction details. Card
+// transactions take precedence over P2P transfers; a payment with neither but
+// a wallet name is a wallet payment. It returns "" when nothing identifies the method.
+func paymentMethod(cards []CardTransaction, p2p []P2PTransaction, wallet string) string {
+	switch {
+	case len(cards) > 0:
+		return PaymentMethodCard
+	case len(p2p) > 0:
+		return PaymentMethodP2P
+	case wallet != "":
+		return PaymentMethodWallet
+	}
+	return ""
+}
+
+// IsCardPayment reports whether the payment was made by card.
+func (p *PaymentDetail) IsCardPayment() bool {
+	return p.PaymentMethod() == PaymentMethodCard
+}
+
+// IsP2PPayment reports whether the payment was made by bank-to-bank (P2P) transfer.
+func (p *PaymentDetail) IsP2PPayment() bool {
+	return p.PaymentMethod() == PaymentMethodP2P
+}
+
+// PaymentMethod returns PaymentMethodCard, PaymentMethodP2P, PaymentMethodWallet,
+// or "" when the response does not identify the method.
+func (p *PaymentDetail) PaymentMethod() string {
+	return paymentMethod(p.CardTransactions, p.P2PTransactions, p.PaymentWallet)
+}
+
+// IsCardPayment reports whether the payment was made by card.
+func (r *PaymentCheckRow) IsCardPayment() bool {
+	return r.PaymentMethod() == PaymentMethodCard
+}
+
+// IsP2PPayment reports whether the payment was made by bank-to-bank (P2P) transfer.
+func (r *PaymentCheckRow) IsP2PPayment() bool {
+	return r.PaymentMethod() == PaymentMethodP2P
+}
+
+// PaymentMethod returns PaymentMethodCard, PaymentMethodP2P, PaymentMethodWallet,
+// or "" when the row does not identify the method.
+func (r *PaymentCheckRow) PaymentMethod() string {
+	return paymentMethod(r.CardTransactions, r.P2PTransactions, r.PaymentWallet)
+}
+
+// Currencies returns the currency the card was charged in and the currency
+// QPay settled in, normalized as by CurrencyCode. For a cross-border card they
+// can differ; an empty TransactionCurrency is taken to equal Currency.
+func (t *CardTransaction) Currencies() (transaction, settlement Currency) {
+	return t.CurrencyCode(), currencyOf(t.Currency)
+}
+
+// crossBorderCards returns the cross-border entries of cards, or nil if none.
+func crossBorderCards(cards []CardTransaction) []CardTransaction {
+	var out []CardTransaction
+	for _, t := range cards {
+		if t.IsCrossBorder {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// HasCrossBorderCard reports whether any card transaction is cross-border,
+// which carries different fees and settlement rules.
+func (p *PaymentDetail) HasCrossBorderCard() bool {
+	return len(crossBorderCards(p.CardTransactions)) > 0
+}
+
+// CrossBorderCards returns the cross-border card transactions; use
+// CardTransaction.Currencies for their transaction and settlement currencies.
+func (p *PaymentDetail) CrossBorderCards() []CardTransaction {
+	return crossBorderCards(p.CardTransactions)
+}
+
+// HasCrossBorderCard reports whether any card transaction is cross-border,
+// which carries different fees and settlement rules.
+func (r *PaymentCheckRow) HasCrossBorderCard() bool {
+	return len(crossBorderCards(r.CardTransactions)) > 0
+}
+
+// CrossBorderCards returns the cross-border card transactions; use
+// CardTransaction.Currencies for their transaction and settlement currencies.
+func (r *PaymentCheckRow) CrossBorderCards() []CardTransaction {
+	return crossBorderCards(r.CardTransactions)
+}
+
+// NetAmount returns PaymentAmount minus PaymentFee. An empty fee counts as zero;
+// a missing or malformed amount returns an error.
+func (p *PaymentDetail) NetAmount() (float64, error) {
+	return netAmount(p.PaymentAmount, "payment_fee", p.PaymentFee)
+}
+
+// NetAmount returns PaymentAmount minus TrxFee. An empty fee counts as zero;
+// a missing or malformed amount returns an error.
+func (r *PaymentCheckRow) NetAmount() (float64, error) {
+	return netAmount(r.PaymentAmount, "trx_fee", r.TrxFee)
+}
+
+func netAmount(amount, feeField, fee string) (float64, error) {
+	a, err := parseAmount("payment_amount", amount)
+	if err != nil {
+		return 0, err
+	}
+	f, err := parseOptionalAmount(feeField, fee)
+	if err != nil {
+		return 0, err
+	}
+	return a - f, nil
+}
+
+// FilterByStatus returns the rows whose PaymentStatus is one of statuses,
+// which must be PaymentStatus constants. QPay's list endpoint has no status
+// filter or sort order, so this filters one fetched page locally; narrow the
+// date range to keep pages small.
+func (r *PaymentListResponse) FilterByStatus(statuses ...string) ([]PaymentListItem, error) {
+	want := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		switch s {
+		case PaymentStatusNew, PaymentStatusFailed, PaymentStatusPaid, PaymentStatusRefunded:
+			want[s] = true
+		default:
+			return nil, &ValidationError{Field: "payment_status", Message: fmt.Sprintf("unknown status %q", s)}
+		}
+	}
+
+	var rows []PaymentListItem
+	for _, row := range r.Rows {
+		if want[row.PaymentStatus] {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// TotalPaid returns the amount paid so far. It uses the server-computed
+// PaidAmount when present, since Rows may hold only one page; otherwise it sums
+// PaymentAmount over rows with PaymentStatusPaid.
+func (r *PaymentCheckResponse) TotalPaid() (float64, error) {
+	if r.PaidAmount != 0 {
+		return r.PaidAmount, nil
+	}
+
+	var total float64
+	for i, row := range r.Rows {
+		if row.PaymentStatus != PaymentStatusPaid {
+			continue
+		}
+		v, err := parseAmount(fmt.Sprintf("rows[%d].payment_amount", i), row.PaymentAmount)
+		if err != nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
+}
+
+// Remaining returns how much of invoiceAmount is still owed after TotalPaid,
+// clamped at zero for overpaid invoices.
+func (r *PaymentCheckResponse) Remaining(invoiceAmount float64) (float64, error) {
+	paid, err := r.TotalPaid()
+	if err != nil {
+		return 0, err
+	}
+	if paid >= invoiceAmount {
+		return 0, nil
+	}
+	return invoiceAmount - paid, nil
+}
@@ -0,0 +1,276 @@
+package qpay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPaymentDetail_PaymentMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		payment  PaymentDetail
+		expected string
+		isCard   bool
+		isP2P    bool
+	}{
+		{
+			name:     "card",
+			payment:  PaymentDetail{CardTransactions: []CardTransaction{{CardType: "VISA"}}, PaymentWallet: "qpay"},
+			expected: PaymentMethodCard,
+			isCard:   true,
+		},
+		{
+			name:     "p2p",
+			payment:  PaymentDetail{P2PTransactions: []P2PTransaction{{AccountBankCode: "050000"}}, PaymentWallet: "qpay"},
+			expected: PaymentMethodP2P,
+			isP2P:    true,
+		},
+		{
+			name:     "card wins over p2p",
+			payment:  PaymentDetail{CardTransactions: []CardTransaction{{}}, P2PTransactions: []P2PTransaction{{}}},
+			expected: PaymentMethodCard,
+			isCard:   true,
+		},
+		{
+			name:     "wallet",
+			payment:  PaymentDetail{PaymentWallet: "qpay"},
+			expected: PaymentMethodWallet,
+		},
+		{
+			name:     "unknown",
+			payment:  PaymentDetail{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.payment.PaymentMethod(); got != tt.expected {
+				t.Errorf("expected method %q, got %q", tt.expected, got)
+			}
+			if got := tt.payment.IsCardPayment(); got != tt.isCard {
+				t.Errorf("expected IsCardPayment %v, got %v", tt.isCard, got)
+			}
+			if got := tt.payment.IsP2PPayment(); got != tt.isP2P {
+				t.Errorf("expected IsP2PPayment %v, got %v", tt.isP2P, got)
+			}
+		})
+	}
+}
+
+func TestPaymentCheckRow_PaymentMethod(t *testing.T) {
+	row := PaymentCheckRow{P2PTransactions: []P2PTransaction{{AccountBankCode: "050000"}}}
+	if got := row.PaymentMethod(); got != PaymentMethodP2P {
+		t.Errorf("expected %q, got %q", PaymentMethodP2P, got)
+	}
+	if !row.IsP2PPayment() || row.IsCardPayment() {
+		t.Error("expected P2P payment only")
+	}
+
+	row = PaymentCheckRow{CardTransactions: []CardTransaction{{CardType: "MASTERCARD"}}}
+	if !row.IsCardPayment() {
+		t.Error("expected card payment")
+	}
+
+	row = PaymentCheckRow{PaymentWallet: "socialpay"}
+	if got := row.PaymentMethod(); got != PaymentMethodWallet {
+		t.Errorf("expected %q, got %q", PaymentMethodWallet, got)
+	}
+}
+
+func TestPaymentDetail_NetAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		fee      string
+		expected float64
+		wantErr  bool
+	}{
+		{"with fee", "50000", "500", 49500, false},
+		{"decimal", "100.50", "1.25", 99.25, false},
+		{"empty fee", "50000", "", 50000, false},
+		{"padded", " 1000 ", " 10 ", 990, false},
+		{"empty amount", "", "10", 0, true},
+		{"malformed amount", "abc", "10", 0, true},
+		{"malformed fee", "1000", "1O", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PaymentDetail{PaymentAmount: tt.amount, PaymentFee: tt.fee}
+			got, err := p.NetAmount()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NetAmount failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPaymentCheckRow_NetAmount(t *testing.T) {
+	row := &PaymentCheckRow{PaymentAmount: "20000", TrxFee: "200"}
+	got, err := row.NetAmount()
+	if err != nil {
+		t.Fatalf("NetAmount failed: %v", err)
+	}
+	if got != 19800 {
+		t.Errorf("expected 19800, got %v", got)
+	}
+
+	row.TrxFee = "n/a"
+	if _, err := row.NetAmount(); err == nil || !strings.Contains(err.Error(), "trx_fee") {
+		t.Errorf("expected trx_fee parse error, got %v", err)
+	}
+}
+
+func TestPaymentCheckResponse_TotalPaid(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     PaymentCheckResponse
+		expected float64
+	}{
+		{
+			name:     "server paid amount",
+			resp:     PaymentCheckResponse{PaidAmount: 3000, Rows: []PaymentCheckRow{{PaymentStatus: PaymentStatusPaid, PaymentAmount: "1000"}}},
+			expected: 3000,
+		},
+		{
+			name: "sum of paid rows",
+			resp: PaymentCheckResponse{Rows: []PaymentCheckRow{
+				{PaymentStatus: PaymentStatusPaid, PaymentAmount: "1000.50"},
+				{PaymentStatus: PaymentStatusFailed, PaymentAmount: "9999"},
+				{PaymentStatus: PaymentStatusPaid, PaymentAmount: "499.50"},
+			}},
+			expected: 1500,
+		},
+		{
+			name:     "nothing paid",
+			resp:     PaymentCheckResponse{},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resp.TotalPaid()
+			if err != nil {
+				t.Fatalf("TotalPaid failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPaymentCheckResponse_TotalPaidMalformed(t *testing.T) {
+	resp := PaymentCheckResponse{Rows: []PaymentCheckRow{
+		{PaymentStatus: PaymentStatusPaid, PaymentAmount: "100"},
+		{PaymentStatus: PaymentStatusPaid, PaymentAmount: "1,000"},
+	}}
+
+	_, err := resp.TotalPaid()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "rows[1].payment_amount") {
+		t.Errorf("expected error to name the row, got %v", err)
+	}
+	if _, err := resp.Remaining(5000); err == nil {
+		t.Error("expected Remaining to surface the parse error")
+	}
+}
+
+func TestPaymentCheckResponse_Remaining(t *testing.T) {
+	resp := PaymentCheckResponse{Rows: []PaymentCheckRow{
+		{PaymentStatus: PaymentStatusPaid, PaymentAmount: "2000"},
+	}}
+
+	got, err := resp.Remaining(5000)
+	if err != nil {
+		t.Fatalf("Remaining failed: %v", err)
+	}
+	if got != 3000 {
+		t.Errorf("expected 3000 remaining, got %v", got)
+	}
+
+	got, err = resp.Remaining(1500)
+	if err != nil {
+		t.Fatalf("Remaining failed: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("expected overpayment clamped to 0, got %v", got)
+	}
+}
+
+func TestHasCrossBorderCard(t *testing.T) {
+	cards := []CardTransaction{
+		{CardType: "VISA", Currency: "MNT"},
+		{CardType: "MASTERCARD", IsCrossBorder: true, Currency: "MNT", TransactionCurrency: "USD"},
+	}
+
+	detail := PaymentDetail{CardTransactions: cards}
+	if !detail.HasCrossBorderCard() {
+		t.Error("expected detail to have a cross-border card")
+	}
+	row := PaymentCheckRow{CardTransactions: cards[:1]}
+	if row.HasCrossBorderCard() {
+		t.Error("expected domestic-only row to have no cross-border card")
+	}
+	if got := row.CrossBorderCards(); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+
+	cross := detail.CrossBorderCards()
+	if len(cross) != 1 || cross[0].CardType != "MASTERCARD" {
+		t.Fatalf("expected the MASTERCARD transaction, got %+v", cross)
+	}
+	trx, settle := cross[0].Currencies()
+	if trx != CurrencyUSD || settle != CurrencyMNT {
+		t.Errorf("expected USD/MNT, got %s/%s", trx, settle)
+	}
+}
+
+func TestCardTransaction_CurrenciesDefault(t *testing.T) {
+	ct := CardTransaction{Currency: "MNT"}
+	trx, settle := ct.Currencies()
+	if trx != CurrencyMNT || settle != CurrencyMNT {
+		t.Errorf("expected MNT/MNT, got %s/%s", trx, settle)
+	}
+
+	ct = CardTransaction{TransactionCurrency: " usd "}
+	if trx, settle := ct.Currencies(); trx != CurrencyUSD || settle != CurrencyMNT {
+		t.Errorf("expected normalized USD/MNT, got %s/%s", trx, settle)
+	}
+}
+
+func TestPaymentListResponse_FilterByStatus(t *testing.T) {
+	resp := PaymentListResponse{Rows: []PaymentListItem{
+		{PaymentID: "1", PaymentStatus: PaymentStatusPaid},
+		{PaymentID: "2", PaymentStatus: PaymentStatusRefunded},
+		{PaymentID: "3", PaymentStatus: PaymentStatusFailed},
+	}}
+
+	rows, err := resp.FilterByStatus(PaymentStatusRefunded, PaymentStatusFailed)
+	if err != nil {
+		t.Fatalf("FilterByStatus failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].PaymentID != "2" || rows[1].PaymentID != "3" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	_, err = resp.FilterByStatus("REFUND")
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "payment_status" {
+		t.Errorf("expected payment_status validation error, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package qpay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeStream decodes a listing one row at a time, so ListPayments doesn't
+// hold a large response body and its decoded rows at once. Fields match
+// case-insensitively as with json.Unmarshal; unknown fields are skipped. An
+// empty body returns io.EOF.
+func (r *PaymentListResponse) decodeStream(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		key, _ := tok.(string)
+		switch strings.ToLower(key) {
+		case "rows":
+			err = r.decodeRows(dec)
+		case "count":
+			err = dec.Decode(&r.Count)
+		case "warnings":
+			err = dec.Decode(&r.Warnings)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+	_, err = dec.Token()
+	return unexpectedEOF(err)
+}
+
+func (r *PaymentListResponse) decodeRows(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		r.Rows = nil
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("rows: expected array, got %v", tok)
+	}
+	r.Rows = make([]PaymentListItem, 0, len(r.Rows))
+	for dec.More() {
+		var item PaymentListItem
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		r.Rows = append(r.Rows, item)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// unexpectedEOF reports a body that ends mid-value as io.ErrUnexpectedEOF,
+// keeping io.EOF for an empty body.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
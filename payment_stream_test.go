@@ -0,0 +1,43 @@
+package qpay
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPaymentListResponse_DecodeStreamMatchesUnmarshal(t *testing.T) {
+	inputs := []string{
+		`{"count":2,"rows":[{"payment_id":"p1","payment_status":"PAID"},{"payment_id":"p2"}],"warnings":["w"]}`,
+		`{"Count":1,"ROWS":[{"payment_id":"p1"}],"extra":{"nested":[1,2,{"a":null}]}}`,
+		`{"count":0,"rows":null}`,
+		`{"rows":[]}`,
+		`null`,
+	}
+	for _, in := range inputs {
+		var want, got PaymentListResponse
+		if err := json.Unmarshal([]byte(in), &want); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", in, err)
+		}
+		if err := got.decodeStream(json.NewDecoder(strings.NewReader(in))); err != nil {
+			t.Fatalf("%s: decodeStream failed: %v", in, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", in, want, got)
+		}
+	}
+}
+
+func TestPaymentListResponse_DecodeStreamErrors(t *testing.T) {
+	var r PaymentListResponse
+	if err := r.decodeStream(json.NewDecoder(strings.NewReader(""))); err != io.EOF {
+		t.Errorf("expected io.EOF for an empty body, got %v", err)
+	}
+	for _, in := range []string{`{"count":1,"rows":[{"payment_id":"p1"}`, `{"count":`, `[1]`, `{"rows":{}}`} {
+		if err := r.decodeStream(json.NewDecoder(strings.NewReader(in))); err == nil || err == io.EOF {
+			t.Errorf("%s: expected an error, got %v", in, err)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package qpay
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"testing"
 )
@@ -442,3 +444,302 @@ func TestRefundPayment_ServerError(t *testing.T) {
 		t.Errorf("expected status 500, got %d", qErr.StatusCode)
 	}
 }
+
+func TestListPayments_NormalizesOffset(t *testing.T) {
+	var got Offset
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req PaymentListRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Offset
+		json.NewEncoder(w).Encode(PaymentListResponse{})
+	})
+	defer server.Close()
+
+	req := &PaymentListRequest{ObjectType: "INVOICE", ObjectID: "inv-1"}
+	if _, err := client.ListPayments(context.Background(), req); err != nil {
+		t.Fatalf("ListPayments failed: %v", err)
+	}
+
+	if got.PageNumber != DefaultPageNumber || got.PageLimit != DefaultPageLimit {
+		t.Errorf("expected offset {%d %d}, got %+v", DefaultPageNumber, DefaultPageLimit, got)
+	}
+	if req.Offset != (Offset{}) {
+		t.Errorf("caller's request was mutated: %+v", req.Offset)
+	}
+}
+
+func TestCheckPayment_NormalizesOffset(t *testing.T) {
+	var got *Offset
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req PaymentCheckRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Offset
+		json.NewEncoder(w).Encode(PaymentCheckResponse{})
+	})
+	defer server.Close()
+
+	_, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{
+		ObjectType: "INVOICE",
+		ObjectID:   "inv-1",
+		Offset:     &Offset{PageLimit: 20},
+	})
+	if err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if got == nil || got.PageNumber != 1 || got.PageLimit != 20 {
+		t.Errorf("expected offset {1 20}, got %+v", got)
+	}
+
+	// A nil Offset is left for QPay to default
+	if _, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"}); err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected nil offset, got %+v", got)
+	}
+}
+
+func TestListPayments_NegativeOffset(t *testing.T) {
+	called := false
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer server.Close()
+
+	_, err := client.ListPayments(context.Background(), &PaymentListRequest{
+		Offset: Offset{PageNumber: -1, PageLimit: 10},
+	})
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if vErr.Field != "offset.page_number" {
+		t.Errorf("unexpected field %q", vErr.Field)
+	}
+	if called {
+		t.Error("request should not reach the server")
+	}
+
+	_, err = client.CheckPayment(context.Background(), &PaymentCheckRequest{Offset: &Offset{PageLimit: -5}})
+	if !errors.As(err, &vErr) || vErr.Field != "offset.page_limit" {
+		t.Errorf("expected page_limit validation error, got %v", err)
+	}
+}
+
+func TestCheckPayment_Warnings(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":0,"rows":[],"warnings":["offset is deprecated","use page_limit"]}`))
+	})
+	defer server.Close()
+
+	resp, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"})
+	if err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if len(resp.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %v", resp.Warnings)
+	}
+}
+
+func TestGetPayment_NoWarnings(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"payment_id":"pay-1"}`))
+	})
+	defer server.Close()
+
+	resp, err := client.GetPayment(context.Background(), "pay-1")
+	if err != nil {
+		t.Fatalf("GetPayment failed: %v", err)
+	}
+	if resp.Warnings != nil {
+		t.Errorf("expected no warnings, got %v", resp.Warnings)
+	}
+}
+
+func TestGetLatestPayment(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/payment/check" {
+			t.Errorf("expected path /v2/payment/check, got %s", r.URL.Path)
+		}
+		var req PaymentCheckRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ObjectType != "INVOICE" || req.ObjectID != "inv-1" {
+			t.Errorf("unexpected check request %+v", req)
+		}
+
+		json.NewEncoder(w).Encode(PaymentCheckResponse{
+			Count: 4,
+			Rows: []PaymentCheckRow{
+				{PaymentID: "old", PaymentStatus: PaymentStatusPaid, PaymentDate: "2024-01-10T08:00:00.000Z"},
+				{PaymentID: "failed", PaymentStatus: PaymentStatusFailed, PaymentDate: "2024-01-12T08:00:00.000Z"},
+				{PaymentID: "new", PaymentStatus: PaymentStatusPaid, PaymentDate: "2024-01-11 09:30:00"},
+				{PaymentID: "undated", PaymentStatus: PaymentStatusPaid},
+			},
+		})
+	})
+	defer server.Close()
+
+	row, err := client.GetLatestPayment(context.Background(), "INVOICE", "inv-1")
+	if err != nil {
+		t.Fatalf("GetLatestPayment failed: %v", err)
+	}
+	if row.PaymentID != "new" {
+		t.Errorf("expected most recent paid payment 'new', got %q", row.PaymentID)
+	}
+}
+
+func TestGetLatestPayment_IgnoresDefaultOffset(t *testing.T) {
+	var got Offset
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req PaymentCheckRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Offset != nil {
+			got = *req.Offset
+		}
+		json.NewEncoder(w).Encode(PaymentCheckResponse{
+			Rows: []PaymentCheckRow{{PaymentID: "p1", PaymentStatus: PaymentStatusPaid}},
+		})
+	})
+	defer server.Close()
+	WithDefaultOffset(Offset{PageNumber: 3, PageLimit: 10})(client)
+
+	if _, err := client.GetLatestPayment(context.Background(), "INVOICE", "inv-1"); err != nil {
+		t.Fatalf("GetLatestPayment failed: %v", err)
+	}
+	if got.PageNumber != DefaultPageNumber || got.PageLimit != DefaultPageLimit {
+		t.Errorf("expected offset {%d %d}, got %+v", DefaultPageNumber, DefaultPageLimit, got)
+	}
+}
+
+func TestGetLatestPayment_UndatedFallback(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentCheckResponse{
+			Rows: []PaymentCheckRow{
+				{PaymentID: "p1", PaymentStatus: PaymentStatusPaid, PaymentDate: "yesterday"},
+			},
+		})
+	})
+	defer server.Close()
+
+	row, err := client.GetLatestPayment(context.Background(), "INVOICE", "inv-1")
+	if err != nil {
+		t.Fatalf("GetLatestPayment failed: %v", err)
+	}
+	if row.PaymentID != "p1" {
+		t.Errorf("expected undated paid row 'p1', got %q", row.PaymentID)
+	}
+}
+
+func TestGetLatestPayment_NoPaidPayment(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentCheckResponse{
+			Rows: []PaymentCheckRow{{PaymentID: "p1", PaymentStatus: PaymentStatusNew}},
+		})
+	})
+	defer server.Close()
+
+	_, err := client.GetLatestPayment(context.Background(), "INVOICE", "inv-1")
+	if !errors.Is(err, ErrNoPaidPayment) {
+		t.Errorf("expected ErrNoPaidPayment, got %v", err)
+	}
+}
+
+func TestWithDefaultOffset_Precedence(t *testing.T) {
+	var got Offset
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req PaymentListRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Offset
+		json.NewEncoder(w).Encode(PaymentListResponse{})
+	})
+	defer server.Close()
+	WithDefaultOffset(Offset{PageLimit: 50})(client)
+
+	tests := []struct {
+		name     string
+		offset   Offset
+		expected Offset
+	}{
+		{"client default then built-in", Offset{}, Offset{PageNumber: DefaultPageNumber, PageLimit: 50}},
+		{"request wins", Offset{PageNumber: 3, PageLimit: 10}, Offset{PageNumber: 3, PageLimit: 10}},
+		{"mixed", Offset{PageNumber: 2}, Offset{PageNumber: 2, PageLimit: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &PaymentListRequest{ObjectType: "INVOICE", ObjectID: "inv-1", Offset: tt.offset}
+			if _, err := client.ListPayments(context.Background(), req); err != nil {
+				t.Fatalf("ListPayments failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithDefaultOffset_CheckPaymentNilOffset(t *testing.T) {
+	var got *Offset
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req PaymentCheckRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Offset
+		json.NewEncoder(w).Encode(PaymentCheckResponse{})
+	})
+	defer server.Close()
+	WithDefaultOffset(Offset{PageLimit: 50})(client)
+
+	req := &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"}
+	if _, err := client.CheckPayment(context.Background(), req); err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if got == nil || got.PageNumber != DefaultPageNumber || got.PageLimit != 50 {
+		t.Errorf("expected default offset {1 50}, got %+v", got)
+	}
+	if req.Offset != nil {
+		t.Errorf("caller's request was mutated: %+v", req.Offset)
+	}
+}
+
+func TestGetPayment_MaintenancePage(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>QPay is under maintenance</body></html>"))
+	})
+	defer server.Close()
+
+	_, err := client.GetPayment(context.Background(), "PAY-1")
+	qErr, ok := IsQPayError(err)
+	if !ok {
+		t.Fatalf("expected QPay error, got %T: %v", err, err)
+	}
+	if qErr.Code != ErrUpstreamUnavailable || qErr.StatusCode != http.StatusOK {
+		t.Errorf("expected UPSTREAM_UNAVAILABLE with status 200, got %+v", qErr)
+	}
+}
+
+func TestCancelAndRefundPayment_NilRequestSendsEmptyObject(t *testing.T) {
+	var bodies, contentTypes []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	if err := client.CancelPayment(context.Background(), "PAY-1", nil); err != nil {
+		t.Fatalf("CancelPayment failed: %v", err)
+	}
+	if err := client.RefundPayment(context.Background(), "PAY-1", nil); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+
+	for i, b := range bodies {
+		if b != "{}" {
+			t.Errorf("request %d: expected body {}, got %q", i+1, b)
+		}
+		if contentTypes[i] != "application/json" {
+			t.Errorf("request %d: expected application/json, got %q", i+1, contentTypes[i])
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package qpaygrpc maps qpay errors to gRPC status codes without depending on
+// google.golang.org/grpc. Code values are the numeric gRPC codes, which the
+// gRPC specification fixes, so they convert directly:
+//
+//	return status.Error(codes.Code(qpaygrpc.CodeOf(err)), err.Error())
+package qpaygrpc
+
+import (
+	"context"
+	"errors"
+
+	qpay "github.com/qpay-sdk/qpay-go"
+)
+
+// Code is a gRPC status code, numerically identical to codes.Code.
+type Code uint32
+
+// gRPC status codes used by CodeOf.
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Internal           Code = 13
+	Unavailable        Code = 14
+	Unauthenticated    Code = 16
+)
+
+// CodeOf returns the gRPC code for err. QPay API errors map by their
+// Category: Auth is Unauthenticated, Permission PermissionDenied, NotFound
+// NotFound, Validation InvalidArgument, Conflict FailedPrecondition,
+// RateLimited ResourceExhausted, Transient Unavailable and Internal Internal.
+// Local validation errors are InvalidArgument, context errors Canceled or
+// DeadlineExceeded, and anything else Unknown. A nil error is OK.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+
+	var qErr *qpay.Error
+	if errors.As(err, &qErr) {
+		return categoryCodes[qErr.Category()]
+	}
+	var vErr *qpay.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		return InvalidArgument
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	}
+	return Unknown
+}
+
+var categoryCodes = map[qpay.ErrorCategory]Code{
+	qpay.CategoryUnknown:     Unknown,
+	qpay.CategoryAuth:        Unauthenticated,
+	qpay.CategoryPermission:  PermissionDenied,
+	qpay.CategoryNotFound:    NotFound,
+	qpay.CategoryValidation:  InvalidArgument,
+	qpay.CategoryConflict:    FailedPrecondition,
+	qpay.CategoryRateLimited: ResourceExhausted,
+	qpay.CategoryTransient:   Unavailable,
+	qpay.CategoryInternal:    Internal,
+}
@@ -0,0 +1,39 @@
+package qpaygrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	qpay "github.com/qpay-sdk/qpay-go"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, OK},
+		{"401", &qpay.Error{StatusCode: 401, Code: qpay.ErrAuthenticationFailed}, Unauthenticated},
+		{"403", &qpay.Error{StatusCode: 403, Code: qpay.ErrPermissionDenied}, PermissionDenied},
+		{"404", &qpay.Error{StatusCode: 404}, NotFound},
+		{"not found code", &qpay.Error{StatusCode: 400, Code: qpay.ErrPaymentNotFound}, NotFound},
+		{"400", &qpay.Error{StatusCode: 400, Code: qpay.ErrInvalidAmount}, InvalidArgument},
+		{"already canceled", &qpay.Error{StatusCode: 400, Code: qpay.ErrPaymentAlreadyCanceled}, FailedPrecondition},
+		{"429", &qpay.Error{StatusCode: 429}, ResourceExhausted},
+		{"500", &qpay.Error{StatusCode: 500}, Internal},
+		{"503", &qpay.Error{StatusCode: 503}, Unavailable},
+		{"wrapped", fmt.Errorf("get: %w", &qpay.Error{StatusCode: 403}), PermissionDenied},
+		{"validation", &qpay.ValidationError{Field: "amount", Message: "must be positive"}, InvalidArgument},
+		{"canceled", fmt.Errorf("request failed: %w", context.Canceled), Canceled},
+		{"deadline", context.DeadlineExceeded, DeadlineExceeded},
+		{"other", errors.New("connection refused"), Unknown},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Package qpaytest provides test doubles for code that depends on qpay.API.
+package qpaytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	qpay "github.com/qpay-sdk/qpay-go"
+)
+
+// ErrNoResponse is returned by RecorderClient methods called without a queued
+// response.
+var ErrNoResponse = errors.New("qpaytest: no response queued")
+
+// Call is one recorded method call. Args holds the arguments after ctx.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// RecorderClient is a qpay.API that records every call and answers with
+// responses queued per method via QueueResponse. It is safe for concurrent use.
+// The zero value is ready to use.
+type RecorderClient struct {
+	mu     sync.Mutex
+	calls  []Call
+	queues map[string][]queued
+}
+
+type queued struct {
+	resp interface{}
+	err  error
+}
+
+var _ qpay.API = (*RecorderClient)(nil)
+
+// QueueResponse queues the result of the next call to method, named as on
+// qpay.API (e.g. "CreateInvoice"). resp must be the method's result type, such
+// as *qpay.InvoiceResponse, or nil; methods that only return an error ignore
+// it. Responses for a method are used in the order they were queued.
+func (r *RecorderClient) QueueResponse(method string, resp interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.queues == nil {
+		r.queues = make(map[string][]queued)
+	}
+	r.queues[method] = append(r.queues[method], queued{resp: resp, err: err})
+}
+
+// Calls returns every recorded call in order.
+func (r *RecorderClient) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the recorded calls to method in order.
+func (r *RecorderClient) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset clears recorded calls and queued responses.
+func (r *RecorderClient) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+	r.queues = nil
+}
+
+// LastInvoiceRequest returns the request of the most recent CreateInvoice
+// call, or nil if there was none.
+func (r *RecorderClient) LastInvoiceRequest() *qpay.CreateInvoiceRequest {
+	req, _ := r.lastArg("CreateInvoice", 0).(*qpay.CreateInvoiceRequest)
+	return req
+}
+
+// LastSimpleInvoiceRequest returns the request of the most recent
+// CreateSimpleInvoice call, or nil if there was none.
+func (r *RecorderClient) LastSimpleInvoiceRequest() *qpay.CreateSimpleInvoiceRequest {
+	req, _ := r.lastArg("CreateSimpleInvoice", 0).(*qpay.CreateSimpleInvoiceRequest)
+	return req
+}
+
+// LastCheckRequest returns the request of the most recent CheckPayment call,
+// or nil if there was none.
+func (r *RecorderClient) LastCheckRequest() *qpay.PaymentCheckRequest {
+	req, _ := r.lastArg("CheckPayment", 0).(*qpay.PaymentCheckRequest)
+	return req
+}
+
+func (r *RecorderClient) lastArg(method string, i int) interface{} {
+	calls := r.CallsTo(method)
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls[len(calls)-1].Args[i]
+}
+
+// record stores the call and pops the next queued response for method.
+func (r *RecorderClient) record(method string, args ...interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+
+	q := r.queues[method]
+	if len(q) == 0 {
+		return nil, fmt.Errorf("%s: %w", method, ErrNoResponse)
+	}
+	r.queues[method] = q[1:]
+	return q[0].resp, q[0].err
+}
+
+func typeError(method string, resp interface{}) error {
+	return fmt.Errorf("qpaytest: %s: queued response has type %T", method, resp)
+}
+
+// Ping records the call and returns the queued error.
+func (r *RecorderClient) Ping(ctx context.Context) error {
+	_, err := r.record("Ping")
+	return err
+}
+
+// CreateInvoice records req and returns the queued response.
+func (r *RecorderClient) CreateInvoice(ctx context.Context, req *qpay.CreateInvoiceRequest) (*qpay.InvoiceResponse, error) {
+	return r.invoice("CreateInvoice", req)
+}
+
+// CreateSimpleInvoice records req and returns the queued response.
+func (r *RecorderClient) CreateSimpleInvoice(ctx context.Context, req *qpay.CreateSimpleInvoiceRequest) (*qpay.InvoiceResponse, error) {
+	return r.invoice("CreateSimpleInvoice", req)
+}
+
+// CreateEbarimtInvoice records req and returns the queued response.
+func (r *RecorderClient) CreateEbarimtInvoice(ctx context.Context, req *qpay.CreateEbarimtInvoiceRequest) (*qpay.InvoiceResponse, error) {
+	return r.invoice("CreateEbarimtInvoice", req)
+}
+
+func (r *RecorderClient) invoice(method string, req interface{}) (*qpay.InvoiceResponse, error) {
+	resp, err := r.record(method, req)
+	if resp == nil {
+		return nil, err
+	}
+	v, ok := resp.(*qpay.InvoiceResponse)
+	if !ok {
+		return nil, typeError(method, resp)
+	}
+	return v, err
+}
+
+// CancelInvoice records invoiceID and returns the queued error.
+func (r *RecorderClient) CancelInvoice(ctx context.Context, invoiceID string) error {
+	_, err := r.record("CancelInvoice", invoiceID)
+	return err
+}
+
+// GetPayment records paymentID and returns the queued response.
+func (r *RecorderClient) GetPayment(ctx context.Context, paymentID string) (*qpay.PaymentDetail, error) {
+	resp, err := r.record("GetPayment", paymentID)
+	if resp == nil {
+		return nil, err
+	}
+	v, ok := resp.(*qpay.PaymentDetail)
+	if !ok {
+		return nil, typeError("GetPayment", resp)
+	}
+	return v, err
+}
+
+// CheckPayment records req and returns the queued response.
+func (r *RecorderClient) CheckPayment(ctx context.Context, req *qpay.PaymentCheckRequest) (*qpay.PaymentCheckResponse, error) {
+	resp, err := r.record("CheckPayment", req)
+	if resp == nil {
+		return nil, err
+	}
+	v, ok := resp.(*qpay.PaymentCheckResponse)
+	if !ok {
+		return nil, typeError("CheckPayment", resp)
+	}
+	return v, err
+}
+
+// ListPayments records req and returns the queued response.
+func (r *RecorderClient) ListPayments(ctx context.Context, req *qpay.PaymentListRequest) (*qpay.PaymentListResponse, error) {
+	resp, err := r.record("ListPayments", req)
+	if resp == nil {
+		return nil, err
+	}
+	v, ok := resp.(*qpay.PaymentListResponse)
+	if !ok {
+		return nil, typeError("ListPayments", resp)
+	}
+	return v, err
+}
+
+// CancelPayment records paymentID and req and returns the queued error.
+func (r *RecorderClient) CancelPayment(ctx context.Context, paymentID string, req *qpay.PaymentCancelRequest) error {
+	_, err := r.record("CancelPayment", paymentID, req)
+	return err
+}
+
+// RefundPayment records paymentID and req and returns the queued error.
+func (r *RecorderClient) RefundPayment(ctx context.Context, paymentID string, req *qpay.PaymentRefundRequest) error {
+	_, err := r.record("RefundPayment", paymentID, req)
+	return err
+}
+
+// CreateEbarimt records req and returns the queued response.
+func (r *RecorderClient) CreateEbarimt(ctx context.Context, req *qpay.CreateEbarimtRequest) (*qpay.EbarimtResponse, error) {
+	return r.ebarimt("CreateEbarimt", req)
+}
+
+// CancelEbarimt records paymentID and returns the queued response.
+func (r *RecorderClient) CancelEbarimt(ctx context.Context, paymentID string) (*qpay.EbarimtResponse, error) {
+	return r.ebarimt("CancelEbarimt", paymentID)
+}
+
+func (r *RecorderClient) ebarimt(method string, arg interface{}) (*qpay.EbarimtResponse, error) {
+	resp, err := r.record(method, arg)
+	if resp == nil {
+		return nil, err
+	}
+	v, ok := resp.(*qpay.EbarimtResponse)
+	if !ok {
+		return nil, typeError(method, resp)
+	}
+	return v, err
+}
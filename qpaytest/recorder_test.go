@@ -0,0 +1,100 @@
+package qpaytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	qpay "github.com/qpay-sdk/qpay-go"
+)
+
+// checkout is the kind of consumer code RecorderClient is meant to test.
+func checkout(ctx context.Context, api qpay.API, orderID string) (string, error) {
+	inv, err := api.CreateInvoice(ctx, &qpay.CreateInvoiceRequest{SenderInvoiceNo: orderID})
+	if err != nil {
+		return "", err
+	}
+	return inv.InvoiceID, nil
+}
+
+func TestRecorderClient_RecordsAndReplays(t *testing.T) {
+	var rec RecorderClient
+	rec.QueueResponse("CreateInvoice", &qpay.InvoiceResponse{InvoiceID: "inv-1"}, nil)
+	rec.QueueResponse("CreateInvoice", nil, &qpay.Error{Code: qpay.ErrInvoiceCodeInvalid})
+
+	id, err := checkout(context.Background(), &rec, "ORD-1")
+	if err != nil || id != "inv-1" {
+		t.Fatalf("expected inv-1, got %q, %v", id, err)
+	}
+	if req := rec.LastInvoiceRequest(); req == nil || req.SenderInvoiceNo != "ORD-1" {
+		t.Errorf("expected recorded request for ORD-1, got %+v", req)
+	}
+
+	_, err = checkout(context.Background(), &rec, "ORD-2")
+	if qErr, ok := qpay.IsQPayError(err); !ok || qErr.Code != qpay.ErrInvoiceCodeInvalid {
+		t.Errorf("expected queued QPay error, got %v", err)
+	}
+	if req := rec.LastInvoiceRequest(); req.SenderInvoiceNo != "ORD-2" {
+		t.Errorf("expected last request ORD-2, got %q", req.SenderInvoiceNo)
+	}
+	if n := len(rec.CallsTo("CreateInvoice")); n != 2 {
+		t.Errorf("expected 2 CreateInvoice calls, got %d", n)
+	}
+}
+
+func TestRecorderClient_NoQueuedResponse(t *testing.T) {
+	var rec RecorderClient
+
+	_, err := rec.CheckPayment(context.Background(), &qpay.PaymentCheckRequest{ObjectID: "inv-1"})
+	if !errors.Is(err, ErrNoResponse) {
+		t.Errorf("expected ErrNoResponse, got %v", err)
+	}
+	if err := rec.CancelInvoice(context.Background(), "inv-1"); !errors.Is(err, ErrNoResponse) {
+		t.Errorf("expected ErrNoResponse, got %v", err)
+	}
+	if req := rec.LastCheckRequest(); req == nil || req.ObjectID != "inv-1" {
+		t.Errorf("expected the call to be recorded, got %+v", req)
+	}
+}
+
+func TestRecorderClient_ErrorOnlyMethods(t *testing.T) {
+	var rec RecorderClient
+	rec.QueueResponse("CancelPayment", nil, nil)
+
+	note := &qpay.PaymentCancelRequest{Note: "n"}
+	if err := rec.CancelPayment(context.Background(), "pay-1", note); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	calls := rec.Calls()
+	if len(calls) != 1 || calls[0].Method != "CancelPayment" {
+		t.Fatalf("expected one CancelPayment call, got %+v", calls)
+	}
+	if calls[0].Args[0] != "pay-1" || calls[0].Args[1] != note {
+		t.Errorf("unexpected args %v", calls[0].Args)
+	}
+}
+
+func TestRecorderClient_WrongResponseType(t *testing.T) {
+	var rec RecorderClient
+	rec.QueueResponse("GetPayment", &qpay.InvoiceResponse{}, nil)
+
+	if _, err := rec.GetPayment(context.Background(), "pay-1"); err == nil {
+		t.Fatal("expected type mismatch error, got nil")
+	}
+}
+
+func TestRecorderClient_Reset(t *testing.T) {
+	var rec RecorderClient
+	rec.QueueResponse("Ping", nil, nil)
+	rec.Ping(context.Background())
+	rec.QueueResponse("Ping", nil, nil)
+	rec.Reset()
+
+	if len(rec.Calls()) != 0 {
+		t.Error("expected no calls after Reset")
+	}
+	if err := rec.Ping(context.Background()); !errors.Is(err, ErrNoResponse) {
+		t.Errorf("expected queued responses cleared, got %v", err)
+	}
+}
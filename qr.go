@@ -0,0 +1,144 @@
+package qpay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrQRChecksum is returned by ParseQR when the QR text's CRC does not match
+// its contents.
+var ErrQRChecksum = errors.New("qpay: QR checksum mismatch")
+
+// QRData is the decoded content of an EMVCo merchant-presented QR code, the
+// format of InvoiceResponse.QRText. Only the fields below are decoded; every
+// top-level field is also available raw in Fields, keyed by its two-digit ID.
+//
+// An invoice's QR identifies the invoice, and QPay charges the invoice's
+// amount; rewriting the amount field (ID 54) produces a QR that banks reject
+// or charge differently. The SDK therefore has no amount-override helper:
+// create an invoice per amount instead.
+type QRData struct {
+	PayloadFormat        string // ID 00
+	InitiationMethod     string // ID 01: "11" static, "12" dynamic
+	MerchantAccounts     map[string]string
+	MerchantCategoryCode string   // ID 52
+	CurrencyNumeric      string   // ID 53, ISO 4217 numeric code
+	Currency             Currency // from CurrencyNumeric; "" if not a supported currency
+	Amount               float64  // ID 54; 0 when absent
+	CountryCode          string   // ID 58
+	MerchantName         string   // ID 59
+	MerchantCity         string   // ID 60
+	PostalCode           string   // ID 61
+	BillNumber           string   // ID 62, sub-ID 01
+	ReferenceLabel       string   // ID 62, sub-ID 05
+	TerminalLabel        string   // ID 62, sub-ID 07
+	CRC                  string   // ID 63
+
+	// Fields holds every top-level field by ID. MerchantAccounts holds IDs
+	// 26 to 51, whose contents are scheme-specific.
+	Fields map[string]string
+}
+
+// numericCurrencies maps ISO 4217 numeric codes to supported currencies.
+var numericCurrencies = map[string]Currency{
+	"496": CurrencyMNT,
+	"840": CurrencyUSD,
+}
+
+// ParseQR decodes EMVCo merchant-presented QR text and verifies its CRC
+// (ID 63, CRC-16/CCITT-FALSE). Malformed TLV data returns an error; a
+// CRC mismatch returns ErrQRChecksum.
+func ParseQR(qrText string) (*QRData, error) {
+	fields, err := parseTLV(qrText)
+	if err != nil {
+		return nil, err
+	}
+
+	crc, ok := fields["63"]
+	if !ok {
+		return nil, fmt.Errorf("qpay: invalid QR: missing CRC field 63")
+	}
+	if !strings.HasSuffix(qrText, "6304"+crc) {
+		return nil, fmt.Errorf("qpay: invalid QR: CRC field 63 must come last")
+	}
+	want := fmt.Sprintf("%04X", crc16CCITT(qrText[:len(qrText)-len(crc)]))
+	if !strings.EqualFold(crc, want) {
+		return nil, ErrQRChecksum
+	}
+
+	d := &QRData{
+		PayloadFormat:        fields["00"],
+		InitiationMethod:     fields["01"],
+		MerchantAccounts:     make(map[string]string),
+		MerchantCategoryCode: fields["52"],
+		CurrencyNumeric:      fields["53"],
+		Currency:             numericCurrencies[fields["53"]],
+		CountryCode:          fields["58"],
+		MerchantName:         fields["59"],
+		MerchantCity:         fields["60"],
+		PostalCode:           fields["61"],
+		CRC:                  crc,
+		Fields:               fields,
+	}
+	for id, v := range fields {
+		if n, _ := strconv.Atoi(id); n >= 26 && n <= 51 {
+			d.MerchantAccounts[id] = v
+		}
+	}
+	if amount, ok := fields["54"]; ok {
+		if d.Amount, err = parseAmount("QR amount", amount); err != nil {
+			return nil, err
+		}
+	}
+	if extra, ok := fields["62"]; ok {
+		sub, err := parseTLV(extra)
+		if err != nil {
+			return nil, fmt.Errorf("qpay: invalid QR additional data: %w", err)
+		}
+		d.BillNumber = sub["01"]
+		d.ReferenceLabel = sub["05"]
+		d.TerminalLabel = sub["07"]
+	}
+	return d, nil
+}
+
+// parseTLV splits EMVCo data into fields of a two-digit ID, a two-digit
+// length and a value of that many bytes.
+func parseTLV(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for i := 0; i < len(s); {
+		if i+4 > len(s) {
+			return nil, fmt.Errorf("qpay: invalid QR: truncated field header at offset %d", i)
+		}
+		id := s[i : i+2]
+		n, err := strconv.Atoi(s[i+2 : i+4])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("qpay: invalid QR: bad length %q for field %s", s[i+2:i+4], id)
+		}
+		i += 4
+		if i+n > len(s) {
+			return nil, fmt.Errorf("qpay: invalid QR: field %s overruns data", id)
+		}
+		fields[id] = s[i : i+n]
+		i += n
+	}
+	return fields, nil
+}
+
+// crc16CCITT computes CRC-16/CCITT-FALSE (polynomial 0x1021, initial 0xFFFF).
+func crc16CCITT(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,90 @@
+package qpay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/qpay-sdk/qpay-go/internal/qrcode"
+)
+
+// pngSignature is the eight-byte header every PNG file starts with.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+const (
+	qrModuleScale = 8 // pixels per module when rendering from QRText
+	qrQuietZone   = 4 // border in modules, as required by ISO/IEC 18004
+)
+
+// WriteQRImage writes the invoice QR code to w as PNG bytes. QRImage, which
+// QPay sends base64-encoded (optionally as a data URI), is decoded as it is
+// written; if it is missing or not a PNG, the code is rendered from QRText
+// instead. No headers are set, so an HTTP handler should set Content-Type
+// itself before calling.
+func (r *InvoiceResponse) WriteQRImage(w io.Writer) error {
+	if r.QRImage != "" {
+		ok, err := writeBase64PNG(w, r.QRImage)
+		if ok || err != nil {
+			return err
+		}
+	}
+	if r.QRText == "" {
+		return ErrNoQR
+	}
+	code, err := qrcode.Encode([]byte(r.QRText))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoQR, err)
+	}
+	return png.Encode(w, code.Image(qrModuleScale, qrQuietZone))
+}
+
+// writeBase64PNG streams base64 PNG data to w. It reports false, writing
+// nothing, when the data does not decode to a PNG header; the error is set
+// only when writing failed or the data was corrupt past the header.
+func writeBase64PNG(w io.Writer, s string) (bool, error) {
+	if i := strings.Index(s, ";base64,"); i >= 0 && strings.HasPrefix(s, "data:") {
+		s = s[i+len(";base64,"):]
+	}
+	dec := bufio.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(s)))
+
+	header, err := dec.Peek(len(pngSignature))
+	if err != nil || !bytes.Equal(header, pngSignature) {
+		return false, nil
+	}
+	if _, err := dec.WriteTo(w); err != nil {
+		return true, fmt.Errorf("failed to stream QR image: %w", err)
+	}
+	return true, nil
+}
+
+// RenderEbarimtQR renders EbarimtQRData, the tax receipt code customers scan
+// to verify the receipt, as PNG bytes at most size pixels wide. Modules are
+// whole pixels, so the image is the largest that fits; a size of 0 or less
+// uses the same scale as WriteQRImage. It returns an error when the receipt
+// has no QR data or size is too small for the code.
+func (e *EbarimtResponse) RenderEbarimtQR(size int) ([]byte, error) {
+	if e.EbarimtQRData == "" {
+		return nil, ErrNoEbarimtQR
+	}
+	code, err := qrcode.Encode([]byte(e.EbarimtQRData))
+	if err != nil {
+		return nil, fmt.Errorf("qpay: encode ebarimt QR: %w", err)
+	}
+
+	scale := qrModuleScale
+	if size > 0 {
+		modules := code.Size + 2*qrQuietZone
+		if scale = size / modules; scale < 1 {
+			return nil, fmt.Errorf("qpay: ebarimt QR needs at least %dpx, got %d", modules, size)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(scale, qrQuietZone)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
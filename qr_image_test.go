@@ -0,0 +1,109 @@
+package qpay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := (&InvoiceResponse{QRText: "test"}).WriteQRImage(&buf); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteQRImage_DecodesBase64(t *testing.T) {
+	want := testPNG(t)
+	encoded := base64.StdEncoding.EncodeToString(want)
+
+	for name, image := range map[string]string{
+		"raw":      encoded,
+		"data URI": "data:image/png;base64," + encoded,
+	} {
+		var buf bytes.Buffer
+		inv := &InvoiceResponse{QRImage: image, QRText: "ignored"}
+		if err := inv.WriteQRImage(&buf); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s: expected the decoded QRImage bytes", name)
+		}
+	}
+}
+
+func TestWriteQRImage_RendersFromText(t *testing.T) {
+	for name, inv := range map[string]*InvoiceResponse{
+		"no image":  {QRText: "0002010102121531279404962794049600022310027138152045734530349654031005802MN"},
+		"not a PNG": {QRImage: base64.StdEncoding.EncodeToString([]byte("GIF89a....")), QRText: "abc"},
+		"bad data":  {QRImage: "!!not base64!!", QRText: "abc"},
+	} {
+		var buf bytes.Buffer
+		if err := inv.WriteQRImage(&buf); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: output is not a PNG: %v", name, err)
+		}
+		if b := img.Bounds(); b.Dx() != b.Dy() || b.Dx()%qrModuleScale != 0 {
+			t.Errorf("%s: unexpected image bounds %v", name, b)
+		}
+	}
+}
+
+func TestWriteQRImage_NothingUsable(t *testing.T) {
+	tests := map[string]*InvoiceResponse{
+		"empty":          {},
+		"bad image only": {QRImage: "bm90IGEgcG5n"},
+		"text too long":  {QRText: string(make([]byte, 3000))},
+	}
+	for name, inv := range tests {
+		var buf bytes.Buffer
+		err := inv.WriteQRImage(&buf)
+		if !errors.Is(err, ErrNoQR) {
+			t.Errorf("%s: expected ErrNoQR, got %v", name, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: expected nothing written, got %d bytes", name, buf.Len())
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestWriteQRImage_WriteError(t *testing.T) {
+	inv := &InvoiceResponse{QRImage: base64.StdEncoding.EncodeToString(testPNG(t))}
+	if err := inv.WriteQRImage(failingWriter{}); err == nil {
+		t.Fatal("expected write error")
+	}
+}
+
+func TestEbarimtResponse_RenderEbarimtQR(t *testing.T) {
+	e := &EbarimtResponse{EbarimtQRData: "1234567890123456789012345678901234567890"}
+
+	data, err := e.RenderEbarimtQR(300)
+	if err != nil {
+		t.Fatalf("RenderEbarimtQR failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a PNG: %v", err)
+	}
+	if w := img.Bounds().Dx(); w > 300 || w < 150 {
+		t.Errorf("expected width near 300px, got %d", w)
+	}
+
+	if _, err := e.RenderEbarimtQR(10); err == nil {
+		t.Error("expected error for a size smaller than the code")
+	}
+	if _, err := (&EbarimtResponse{}).RenderEbarimtQR(300); !errors.Is(err, ErrNoEbarimtQR) {
+		t.Errorf("expected ErrNoEbarimtQR, got %v", err)
+	}
+}
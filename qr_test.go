@@ -0,0 +1,100 @@
+package qpay
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// tlv encodes one EMVCo field.
+func tlv(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// withCRC appends a valid CRC field to payload.
+func withCRC(payload string) string {
+	payload += "6304"
+	return payload + fmt.Sprintf("%04X", crc16CCITT(payload))
+}
+
+func testQRText() string {
+	return withCRC(tlv("00", "01") +
+		tlv("01", "12") +
+		tlv("26", tlv("00", "mn.qpay")+tlv("01", "MERCHANT1")) +
+		tlv("52", "5411") +
+		tlv("53", "496") +
+		tlv("54", "15000.50") +
+		tlv("58", "MN") +
+		tlv("59", "Test Shop") +
+		tlv("60", "Ulaanbaatar") +
+		tlv("62", tlv("01", "INV-001")+tlv("05", "REF-9")+tlv("07", "T1")))
+}
+
+func TestCRC16CCITT(t *testing.T) {
+	if got := crc16CCITT("123456789"); got != 0x29B1 {
+		t.Errorf("expected check value 0x29B1, got %#04x", got)
+	}
+}
+
+func TestParseQR(t *testing.T) {
+	d, err := ParseQR(testQRText())
+	if err != nil {
+		t.Fatalf("ParseQR failed: %v", err)
+	}
+
+	checks := map[string][2]string{
+		"PayloadFormat":        {d.PayloadFormat, "01"},
+		"InitiationMethod":     {d.InitiationMethod, "12"},
+		"MerchantCategoryCode": {d.MerchantCategoryCode, "5411"},
+		"CurrencyNumeric":      {d.CurrencyNumeric, "496"},
+		"Currency":             {string(d.Currency), "MNT"},
+		"CountryCode":          {d.CountryCode, "MN"},
+		"MerchantName":         {d.MerchantName, "Test Shop"},
+		"MerchantCity":         {d.MerchantCity, "Ulaanbaatar"},
+		"BillNumber":           {d.BillNumber, "INV-001"},
+		"ReferenceLabel":       {d.ReferenceLabel, "REF-9"},
+		"TerminalLabel":        {d.TerminalLabel, "T1"},
+	}
+	for name, c := range checks {
+		if c[0] != c[1] {
+			t.Errorf("%s: expected %q, got %q", name, c[1], c[0])
+		}
+	}
+	if d.Amount != 15000.50 {
+		t.Errorf("expected amount 15000.50, got %v", d.Amount)
+	}
+	if d.MerchantAccounts["26"] != tlv("00", "mn.qpay")+tlv("01", "MERCHANT1") {
+		t.Errorf("unexpected merchant account data %q", d.MerchantAccounts["26"])
+	}
+	if len(d.Fields) != 11 {
+		t.Errorf("expected 11 raw fields, got %d", len(d.Fields))
+	}
+}
+
+func TestParseQR_ChecksumMismatch(t *testing.T) {
+	text := testQRText()
+	tampered := text[:len(text)-4] + "0000"
+
+	if _, err := ParseQR(tampered); !errors.Is(err, ErrQRChecksum) {
+		t.Errorf("expected ErrQRChecksum, got %v", err)
+	}
+}
+
+func TestParseQR_Malformed(t *testing.T) {
+	tests := map[string]string{
+		"no crc":          tlv("00", "01"),
+		"truncated":       "000201015",
+		"overrun":         "0099ab",
+		"bad length":      "00xx01",
+		"crc not last":    tlv("63", "ABCD") + tlv("00", "01"),
+		"bad amount":      withCRC(tlv("00", "01") + tlv("54", "1,000")),
+		"bad subfields":   withCRC(tlv("00", "01") + tlv("62", "0199")),
+		"empty qr text":   "",
+		"crc wrong width": tlv("00", "01") + "6302AB",
+	}
+	for name, text := range tests {
+		if _, err := ParseQR(text); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package qpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// quickReceiverCode is the invoice_receiver_code QuickInvoiceQR sends, QPay's
+// placeholder for invoices not addressed to a registered customer.
+const quickReceiverCode = "terminal"
+
+// QuickInvoiceQR creates a simple invoice for amount and description and
+// returns its QR code as PNG bytes, its short URL and its ID. The invoice code
+// defaults as in CreateSimpleInvoice and the callback URL is
+// Config.CallbackURL, which must be set. The sender invoice number is random,
+// so retrying a failed call may create a second invoice; use
+// CreateSimpleInvoice with your own number when that matters.
+//
+// If the QR code can't be rendered, the invoice ID is still returned with the
+// error so the invoice can be canceled.
+func (c *Client) QuickInvoiceQR(ctx context.Context, amount float64, description string) (png []byte, shortURL, invoiceID string, err error) {
+	if c.config.CallbackURL == "" {
+		return nil, "", "", &ValidationError{Field: "callback_url", Message: "Config.CallbackURL is required"}
+	}
+	senderInvoiceNo, err := quickInvoiceNo()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	invoice, err := c.CreateSimpleInvoice(ctx, &CreateSimpleInvoiceRequest{
+		SenderInvoiceNo:     senderInvoiceNo,
+		InvoiceReceiverCode: quickReceiverCode,
+		InvoiceDescription:  description,
+		Amount:              amount,
+		CallbackURL:         c.config.CallbackURL,
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var buf bytes.Buffer
+	if err := invoice.WriteQRImage(&buf); err != nil {
+		return nil, invoice.QPay_ShortURL, invoice.InvoiceID, err
+	}
+	return buf.Bytes(), invoice.QPay_ShortURL, invoice.InvoiceID, nil
+}
+
+// quickInvoiceNo returns a random sender invoice number.
+func quickInvoiceNo() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "quick-" + hex.EncodeToString(b), nil
+}
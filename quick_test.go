@@ -0,0 +1,65 @@
+package qpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQuickInvoiceQR(t *testing.T) {
+	var got CreateSimpleInvoiceRequest
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(InvoiceResponse{
+			InvoiceID:     "inv-1",
+			QRText:        "0002010102121531279404962794049600022310027138152045734530349654031005802MN",
+			QPay_ShortURL: "https://s.qpay.mn/abc",
+		})
+	})
+	defer server.Close()
+
+	png, shortURL, invoiceID, err := client.QuickInvoiceQR(context.Background(), 5000, "Coffee")
+	if err != nil {
+		t.Fatalf("QuickInvoiceQR failed: %v", err)
+	}
+	if !bytes.HasPrefix(png, pngSignature) {
+		t.Error("expected PNG bytes")
+	}
+	if shortURL != "https://s.qpay.mn/abc" || invoiceID != "inv-1" {
+		t.Errorf("unexpected short URL %q or ID %q", shortURL, invoiceID)
+	}
+	if got.InvoiceCode != "TEST_INVOICE" || got.CallbackURL != "https://example.com/callback" {
+		t.Errorf("expected Config defaults, got %+v", got)
+	}
+	if got.Amount != 5000 || got.InvoiceDescription != "Coffee" || !strings.HasPrefix(got.SenderInvoiceNo, "quick-") {
+		t.Errorf("unexpected request %+v", got)
+	}
+}
+
+func TestQuickInvoiceQR_NoQRKeepsInvoiceID(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-1"})
+	})
+	defer server.Close()
+
+	_, _, invoiceID, err := client.QuickInvoiceQR(context.Background(), 5000, "Coffee")
+	if !errors.Is(err, ErrNoQR) {
+		t.Errorf("expected ErrNoQR, got %v", err)
+	}
+	if invoiceID != "inv-1" {
+		t.Errorf("expected invoice ID for cleanup, got %q", invoiceID)
+	}
+}
+
+func TestQuickInvoiceQR_RequiresCallbackURL(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://example.invalid", InvoiceCode: "CODE"})
+	_, _, _, err := client.QuickInvoiceQR(context.Background(), 5000, "Coffee")
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "callback_url" {
+		t.Errorf("expected callback_url validation error, got %v", err)
+	}
+}
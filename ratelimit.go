@@ -0,0 +1,14 @@
+package qpay
+
+import "golang.org/x/time/rate"
+
+// WithRateLimit limits the client to r requests per second with bursts of
+// up to burst requests, using a token bucket shared by every method on the
+// client. Each attempt, including retries and token requests, waits for the
+// limiter before it is sent. The wait fails without sending if the request
+// context is done, or if its deadline would pass before a token is free.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(r, burst)
+	}
+}
@@ -0,0 +1,86 @@
+package qpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWithRateLimit_SharedAcrossMethods(t *testing.T) {
+	var hits atomic.Int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	// Burst of 2 covers the token request and one API call.
+	WithRateLimit(rate.Every(time.Hour), 2)(client)
+
+	if _, err := client.GetPayment(context.Background(), "PAY-1"); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.CancelPayment(ctx, "PAY-1", &PaymentCancelRequest{})
+	if err == nil {
+		t.Fatal("expected the limiter to reject a request over the limit")
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 API request to reach the server, got %d", hits.Load())
+	}
+}
+
+func TestWithRateLimit_ContextCanceled(t *testing.T) {
+	var hits atomic.Int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	WithRateLimit(rate.Every(time.Hour), 1)(client)
+	client.accessToken = "preset"
+	client.expiresAt = time.Now().Add(time.Hour).Unix()
+
+	if _, err := client.GetPayment(context.Background(), "PAY-1"); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := client.GetPayment(ctx, "PAY-2")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", hits.Load())
+	}
+}
+
+func TestWithRateLimit_Waits(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+	WithRateLimit(rate.Every(30*time.Millisecond), 1)(client)
+	client.accessToken = "preset"
+	client.expiresAt = time.Now().Add(time.Hour).Unix()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetPayment(context.Background(), "PAY-1"); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected requests to be spaced by the limiter, took %v", elapsed)
+	}
+}
@@ -0,0 +1,144 @@
+package qpay
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Jitter selects how RetryPolicy randomizes backoff delays.
+type Jitter int
+
+const (
+	// JitterFull waits a random duration between zero and the computed
+	// backoff, so clients that failed together don't retry together.
+	JitterFull Jitter = iota
+	// JitterNone waits exactly the computed backoff.
+	JitterNone
+)
+
+// RetryPolicy controls how the client retries requests that fail transiently.
+// The backoff before retry n is BaseDelay*2^(n-1), capped at MaxDelay, then
+// jittered. A context deadline is a ceiling for all attempts and backoffs
+// together; if the next backoff would end past it, the client stops and
+// returns the last failure instead.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter defaults to JitterFull.
+	Jitter Jitter
+	// ShouldRetry decides whether to retry after a failed attempt; it
+	// defaults to DefaultShouldRetry. See ShouldRetryFunc.
+	ShouldRetry ShouldRetryFunc
+}
+
+// ShouldRetryFunc reports whether to retry after attempt (1-based) failed. For
+// a transport failure resp is nil and err is the error. For a non-2xx response
+// or an HTML page resp is the response, with a re-readable body, and err is
+// the decoded *Error, so predicates can match on its Code. It is not called
+// for 2xx JSON responses, once MaxAttempts is reached, or after ctx is done.
+type ShouldRetryFunc func(resp *http.Response, err error, attempt int) bool
+
+// DefaultShouldRetry retries transport errors, 429, 502, 503 and 504
+// responses, and HTML maintenance pages (ErrUpstreamUnavailable).
+func DefaultShouldRetry(resp *http.Response, err error, attempt int) bool {
+	if resp == nil {
+		return err != nil
+	}
+	if qErr, ok := err.(*Error); ok && qErr.Code == ErrUpstreamUnavailable {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// DefaultRetryPolicy returns a policy of three attempts with full jitter
+// over a 200ms base delay, capped at 5s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// WithRetryPolicy enables retries with the given policy. Without it, each
+// request is attempted once.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithRandSource sets the random source used for retry jitter. Inject a
+// fixed-seed source to make backoff delays deterministic in tests.
+func WithRandSource(src rand.Source) Option {
+	return func(c *Client) {
+		c.rand = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// lockedRand is a rand.Rand safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) int63n(n int64) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
+}
+
+// backoff returns the delay before retry number attempt (1-based). A zero
+// MaxDelay leaves it uncapped, short of overflowing time.Duration.
+func (p RetryPolicy) backoff(attempt int, rnd *lockedRand) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter == JitterFull && d > 0 {
+		d = time.Duration(rnd.int63n(int64(d) + 1))
+	}
+	return d
+}
+
+// shouldRetry applies the policy's predicate to a finished attempt.
+func (p RetryPolicy) shouldRetry(resp *http.Response, body []byte, err error, attempt int) bool {
+	if err == nil {
+		qErr := responseError(resp, body)
+		if qErr == nil {
+			return false
+		}
+		err = qErr
+	}
+	should := p.ShouldRetry
+	if should == nil {
+		should = DefaultShouldRetry
+	}
+	return should(resp, err, attempt)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,330 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_RetriesTransientStatus(t *testing.T) {
+	var calls int32
+	var bodies []string
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"count":1}`))
+	})
+	defer server.Close()
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Jitter: JitterNone})(client)
+
+	resp, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"})
+	if err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if resp.Count != 1 {
+		t.Errorf("expected count 1, got %d", resp.Count)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	for i, b := range bodies {
+		if b == "" || b != bodies[0] {
+			t.Errorf("attempt %d: expected body %q, got %q", i+1, bodies[0], b)
+		}
+	}
+}
+
+func TestRetryPolicy_ResendsCreateInvoiceBody(t *testing.T) {
+	var bodies []string
+	var lengths []int64
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		lengths = append(lengths, r.ContentLength)
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"invoice_id":"inv-1"}`))
+	})
+	defer server.Close()
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, Jitter: JitterNone})(client)
+
+	req := lookupRequest()
+	want, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CreateInvoice(context.Background(), req); err != nil {
+		t.Fatalf("CreateInvoice failed: %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(bodies))
+	}
+	for i, b := range bodies {
+		if b != string(want) || lengths[i] != int64(len(want)) {
+			t.Errorf("attempt %d: sent %d bytes %q, want %q", i+1, lengths[i], b, want)
+		}
+	}
+}
+
+func TestRetryPolicy_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})(client)
+
+	_, err := client.GetPayment(context.Background(), "pay-1")
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_NoRetryByDefaultOrOnClientErrors(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/v2/payment/bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client.GetPayment(context.Background(), "pay-1")
+	if calls != 1 {
+		t.Errorf("expected a single attempt without a retry policy, got %d", calls)
+	}
+
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})(client)
+	calls = 0
+	client.GetPayment(context.Background(), "bad")
+	if calls != 1 {
+		t.Errorf("expected 400 not to be retried, got %d attempts", calls)
+	}
+}
+
+func TestRetryPolicy_StopsWhenContextDone(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+	client.ensureToken(context.Background())
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, Jitter: JitterNone})(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetPayment(ctx, "pay-1")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected retry loop to stop with the context, took %v", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_StopsBeforeBackoffPastDeadline(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+	client.ensureToken(context.Background())
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: 150 * time.Millisecond, MaxDelay: 2 * time.Second, Jitter: JitterNone})(client)
+
+	// Attempts and backoffs would take 150+300+600+1200ms; the deadline
+	// allows two attempts at most.
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetPayment(ctx, "pay-1")
+	elapsed := time.Since(start)
+
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last 503 error, got %v", err)
+	}
+	if elapsed >= 400*time.Millisecond {
+		t.Errorf("expected to stop before the deadline, took %v", elapsed)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: JitterNone}
+
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, want := range expected {
+		if got := p.backoff(i+1, nil); got != want*time.Millisecond {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffUncapped(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, Jitter: JitterNone}
+
+	expected := []time.Duration{100, 200, 400, 800, 1600, 3200}
+	for i, want := range expected {
+		if got := p.backoff(i+1, nil); got != want*time.Millisecond {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+	if got := p.backoff(200, nil); got <= 0 {
+		t.Errorf("expected a huge attempt not to overflow, got %v", got)
+	}
+}
+
+func TestRetryPolicy_FullJitterDeterministic(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	a := &lockedRand{r: rand.New(rand.NewSource(42))}
+	b := &lockedRand{r: rand.New(rand.NewSource(42))}
+
+	varied := false
+	for attempt := 1; attempt <= 5; attempt++ {
+		da, db := p.backoff(attempt, a), p.backoff(attempt, b)
+		if da != db {
+			t.Errorf("attempt %d: expected equal delays from equal seeds, got %v and %v", attempt, da, db)
+		}
+		ceiling := p
+		ceiling.Jitter = JitterNone
+		if max := ceiling.backoff(attempt, nil); da < 0 || da > max {
+			t.Errorf("attempt %d: delay %v outside [0, %v]", attempt, da, max)
+		} else if da != max {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("expected jitter to vary delays below the computed backoff")
+	}
+}
+
+func TestWithRandSource(t *testing.T) {
+	client := NewClient(&Config{}, WithRandSource(rand.NewSource(1)))
+	want := rand.New(rand.NewSource(1)).Int63n(1000)
+	if got := client.rand.int63n(1000); got != want {
+		t.Errorf("expected injected source to be used, got %d want %d", got, want)
+	}
+}
+
+func TestRetryPolicy_CustomShouldRetry(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(520)
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"PAYMENT_NOT_PAID","message":"not yet"}`))
+		case 3:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"INVALID_AMOUNT","message":"bad amount"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	defer server.Close()
+
+	var attempts []int
+	WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Millisecond,
+		ShouldRetry: func(resp *http.Response, err error, attempt int) bool {
+			attempts = append(attempts, attempt)
+			if resp != nil && resp.StatusCode == 520 {
+				return true
+			}
+			if qErr, ok := IsQPayError(err); ok {
+				return qErr.Code == ErrPaymentNotPaid
+			}
+			return DefaultShouldRetry(resp, err, attempt)
+		},
+	})(client)
+
+	_, err := client.GetPayment(context.Background(), "pay-1")
+	qErr, ok := IsQPayError(err)
+	if !ok || qErr.Code != ErrInvalidAmount {
+		t.Fatalf("expected INVALID_AMOUNT to stop retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("expected predicate called for attempts 1-3, got %v", attempts)
+	}
+}
+
+func TestDefaultShouldRetry(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected bool
+	}{
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status}
+		if got := DefaultShouldRetry(resp, decodeError(tt.status, nil), 1); got != tt.expected {
+			t.Errorf("status %d: expected %v, got %v", tt.status, tt.expected, got)
+		}
+	}
+	if !DefaultShouldRetry(nil, io.ErrUnexpectedEOF, 1) {
+		t.Error("expected transport errors to be retried")
+	}
+}
+
+func TestRetryPolicy_RetriesMaintenancePage(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body>Maintenance</body></html>"))
+			return
+		}
+		w.Write([]byte(`{"count":1}`))
+	})
+	defer server.Close()
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})(client)
+
+	resp, err := client.CheckPayment(context.Background(), &PaymentCheckRequest{ObjectType: "INVOICE", ObjectID: "inv-1"})
+	if err != nil {
+		t.Fatalf("CheckPayment failed: %v", err)
+	}
+	if resp.Count != 1 || calls != 2 {
+		t.Errorf("expected a retried success, got count %d after %d attempts", resp.Count, calls)
+	}
+}
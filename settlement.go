@@ -0,0 +1,96 @@
+package qpay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SettlementStatusSettled is the SettlementStatus of a card or P2P
+// transaction whose funds have reached the merchant.
+const SettlementStatusSettled = "SETTLED"
+
+// settlementStatus returns SettlementStatusSettled when every card and P2P
+// transaction is settled, otherwise the first unsettled status, or "" when
+// the payment has no transactions yet.
+func (p *PaymentDetail) settlementStatus() string {
+	if len(p.CardTransactions) == 0 && len(p.P2PTransactions) == 0 {
+		return ""
+	}
+	for _, t := range p.CardTransactions {
+		if !strings.EqualFold(t.SettlementStatus, SettlementStatusSettled) {
+			return t.SettlementStatus
+		}
+	}
+	for _, t := range p.P2PTransactions {
+		if !strings.EqualFold(t.SettlementStatus, SettlementStatusSettled) {
+			return t.SettlementStatus
+		}
+	}
+	return SettlementStatusSettled
+}
+
+// IsSettled reports whether the payment has card or P2P transactions and all
+// of them are settled. Wallet payments without transactions never are.
+func (p *PaymentDetail) IsSettled() bool {
+	return p.settlementStatus() == SettlementStatusSettled
+}
+
+// SettlementDate returns the latest SettlementStatusDate among the card
+// transactions, or the zero time if none has one. P2P transactions carry no
+// settlement date.
+func (p *PaymentDetail) SettlementDate() (time.Time, error) {
+	var latest time.Time
+	for i, t := range p.CardTransactions {
+		if strings.TrimSpace(t.SettlementStatusDate) == "" {
+			continue
+		}
+		at, err := parseTime(fmt.Sprintf("card_transactions[%d].settlement_status_date", i), t.SettlementStatusDate)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if at.After(latest) {
+			latest = at
+		}
+	}
+	return latest, nil
+}
+
+// WaitForSettlement polls GetPayment until every card and P2P transaction of
+// the payment is settled, which usually happens well after it is PAID, and
+// returns the settled payment; see SettlementDate for when. It gives up when
+// ctx is done or a fetch fails; set ctx's deadline to bound the wait. opts may
+// be nil. OnStatusChange receives SettlementStatusSettled once settled,
+// otherwise the first unsettled transaction's status, or "" while there are
+// no transactions.
+func (c *Client) WaitForSettlement(ctx context.Context, paymentID string, opts *WaitOptions) (*PaymentDetail, error) {
+	var o WaitOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultPollInterval
+	}
+
+	var last string
+	for first := true; ; first = false {
+		payment, err := c.GetPayment(ctx, paymentID)
+		if err != nil {
+			return nil, err
+		}
+
+		status := payment.settlementStatus()
+		if o.OnStatusChange != nil && (first || status != last) {
+			o.OnStatusChange(status)
+		}
+		last = status
+
+		if status == SettlementStatusSettled {
+			return payment, nil
+		}
+		if err := sleep(ctx, o.Interval); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForSettlement(t *testing.T) {
+	var fetches int32
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/payment/pay-1" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		card := CardTransaction{SettlementStatus: "PENDING"}
+		switch atomic.AddInt32(&fetches, 1) {
+		case 1:
+			json.NewEncoder(w).Encode(PaymentDetail{PaymentID: "pay-1", PaymentStatus: PaymentStatusPaid})
+			return
+		case 2:
+		default:
+			card.SettlementStatus = SettlementStatusSettled
+			card.SettlementStatusDate = "2024-03-02 10:00:00"
+		}
+		json.NewEncoder(w).Encode(PaymentDetail{
+			PaymentID:        "pay-1",
+			PaymentStatus:    PaymentStatusPaid,
+			CardTransactions: []CardTransaction{card},
+		})
+	})
+	defer server.Close()
+
+	var statuses []string
+	payment, err := client.WaitForSettlement(context.Background(), "pay-1", &WaitOptions{
+		Interval:       time.Millisecond,
+		OnStatusChange: func(status string) { statuses = append(statuses, status) },
+	})
+	if err != nil {
+		t.Fatalf("WaitForSettlement failed: %v", err)
+	}
+	if !payment.IsSettled() {
+		t.Error("expected settled payment")
+	}
+	if want := []string{"", "PENDING", SettlementStatusSettled}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("expected statuses %v, got %v", want, statuses)
+	}
+
+	at, err := payment.SettlementDate()
+	if err != nil {
+		t.Fatalf("SettlementDate failed: %v", err)
+	}
+	if want := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC); !at.Equal(want) {
+		t.Errorf("expected %v, got %v", want, at)
+	}
+}
+
+func TestWaitForSettlement_ContextDeadline(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentDetail{
+			PaymentID:       "pay-1",
+			P2PTransactions: []P2PTransaction{{SettlementStatus: "PENDING"}},
+		})
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForSettlement(ctx, "pay-1", &WaitOptions{Interval: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPaymentDetail_IsSettled(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment PaymentDetail
+		want    bool
+	}{
+		{"no transactions", PaymentDetail{PaymentWallet: "qPay"}, false},
+		{"card settled", PaymentDetail{CardTransactions: []CardTransaction{{SettlementStatus: "settled"}}}, true},
+		{"p2p pending", PaymentDetail{
+			CardTransactions: []CardTransaction{{SettlementStatus: SettlementStatusSettled}},
+			P2PTransactions:  []P2PTransaction{{SettlementStatus: "PENDING"}},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.payment.IsSettled(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPaymentDetail_SettlementDate(t *testing.T) {
+	p := PaymentDetail{CardTransactions: []CardTransaction{
+		{SettlementStatusDate: "2024-03-01T09:00:00Z"},
+		{SettlementStatusDate: "2024-03-02T09:00:00Z"},
+		{},
+	}}
+	at, err := p.SettlementDate()
+	if err != nil {
+		t.Fatalf("SettlementDate failed: %v", err)
+	}
+	if at.Day() != 2 {
+		t.Errorf("expected the latest date, got %v", at)
+	}
+
+	p.CardTransactions[2].SettlementStatusDate = "yesterday"
+	if _, err := p.SettlementDate(); err == nil {
+		t.Error("expected error for malformed date")
+	}
+
+	if at, err := (&PaymentDetail{}).SettlementDate(); err != nil || !at.IsZero() {
+		t.Errorf("expected zero time, got %v, %v", at, err)
+	}
+}
@@ -0,0 +1,140 @@
+package qpay
+
+import (
+	"context"
+	"time"
+)
+
+// defaultSimpleTimeout bounds SimpleClient calls when the HTTP client has no timeout.
+const defaultSimpleTimeout = 30 * time.Second
+
+// SimpleClient wraps a Client for scripts that don't need context handling.
+// Each call runs with context.Background() bounded by the HTTP client's timeout.
+type SimpleClient struct {
+	client *Client
+}
+
+// Simple returns a SimpleClient backed by c. It shares c's configuration and tokens.
+func (c *Client) Simple() *SimpleClient {
+	return &SimpleClient{client: c}
+}
+
+func (s *SimpleClient) context() (context.Context, context.CancelFunc) {
+	timeout := s.client.http.Timeout
+	if timeout <= 0 {
+		timeout = defaultSimpleTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// GetToken calls Client.GetToken.
+func (s *SimpleClient) GetToken() (*TokenResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.GetToken(ctx)
+}
+
+// RefreshToken calls Client.RefreshToken.
+func (s *SimpleClient) RefreshToken() (*TokenResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.RefreshToken(ctx)
+}
+
+// Ping calls Client.Ping.
+func (s *SimpleClient) Ping() error {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.Ping(ctx)
+}
+
+// CreateInvoice calls Client.CreateInvoice.
+func (s *SimpleClient) CreateInvoice(req *CreateInvoiceRequest) (*InvoiceResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CreateInvoice(ctx, req)
+}
+
+// CreateSimpleInvoice calls Client.CreateSimpleInvoice.
+func (s *SimpleClient) CreateSimpleInvoice(req *CreateSimpleInvoiceRequest) (*InvoiceResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CreateSimpleInvoice(ctx, req)
+}
+
+// CreateEbarimtInvoice calls Client.CreateEbarimtInvoice.
+func (s *SimpleClient) CreateEbarimtInvoice(req *CreateEbarimtInvoiceRequest) (*InvoiceResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CreateEbarimtInvoice(ctx, req)
+}
+
+// CancelInvoice calls Client.CancelInvoice.
+func (s *SimpleClient) CancelInvoice(invoiceID string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CancelInvoice(ctx, invoiceID)
+}
+
+// CancelInvoiceWithResult calls Client.CancelInvoiceWithResult.
+func (s *SimpleClient) CancelInvoiceWithResult(invoiceID string) (*CancelInvoiceResult, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CancelInvoiceWithResult(ctx, invoiceID)
+}
+
+// GetPayment calls Client.GetPayment.
+func (s *SimpleClient) GetPayment(paymentID string) (*PaymentDetail, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.GetPayment(ctx, paymentID)
+}
+
+// CheckPayment calls Client.CheckPayment.
+func (s *SimpleClient) CheckPayment(req *PaymentCheckRequest) (*PaymentCheckResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CheckPayment(ctx, req)
+}
+
+// GetLatestPayment calls Client.GetLatestPayment.
+func (s *SimpleClient) GetLatestPayment(objectType, objectID string) (*PaymentCheckRow, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.GetLatestPayment(ctx, objectType, objectID)
+}
+
+// ListPayments calls Client.ListPayments.
+func (s *SimpleClient) ListPayments(req *PaymentListRequest) (*PaymentListResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.ListPayments(ctx, req)
+}
+
+// CancelPayment calls Client.CancelPayment.
+func (s *SimpleClient) CancelPayment(paymentID string, req *PaymentCancelRequest) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CancelPayment(ctx, paymentID, req)
+}
+
+// RefundPayment calls Client.RefundPayment.
+func (s *SimpleClient) RefundPayment(paymentID string, req *PaymentRefundRequest) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.RefundPayment(ctx, paymentID, req)
+}
+
+// CreateEbarimt calls Client.CreateEbarimt.
+func (s *SimpleClient) CreateEbarimt(req *CreateEbarimtRequest) (*EbarimtResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CreateEbarimt(ctx, req)
+}
+
+// CancelEbarimt calls Client.CancelEbarimt.
+func (s *SimpleClient) CancelEbarimt(paymentID string) (*EbarimtResponse, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.CancelEbarimt(ctx, paymentID)
+}
@@ -0,0 +1,64 @@
+package qpay
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSimpleClient_CreateSimpleInvoice(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(InvoiceResponse{InvoiceID: "inv-simple"})
+	})
+	defer server.Close()
+
+	resp, err := client.Simple().CreateSimpleInvoice(&CreateSimpleInvoiceRequest{
+		SenderInvoiceNo: "ORDER-1",
+		Amount:          1000,
+	})
+	if err != nil {
+		t.Fatalf("CreateSimpleInvoice failed: %v", err)
+	}
+	if resp.InvoiceID != "inv-simple" {
+		t.Errorf("expected invoice ID 'inv-simple', got %q", resp.InvoiceID)
+	}
+}
+
+func TestSimpleClient_SharesToken(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PaymentDetail{PaymentID: "pay-1"})
+	})
+	defer server.Close()
+
+	if _, err := client.Simple().GetPayment("pay-1"); err != nil {
+		t.Fatalf("GetPayment failed: %v", err)
+	}
+	if client.accessToken != "test-access-token" {
+		t.Errorf("expected token stored on underlying client, got %q", client.accessToken)
+	}
+}
+
+func TestSimpleClient_ContextTimeout(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn"})
+	client.http.Timeout = 5 * time.Second
+
+	ctx, cancel := client.Simple().context()
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline")
+	}
+	if remaining := time.Until(deadline); remaining > 5*time.Second || remaining < 4*time.Second {
+		t.Errorf("expected ~5s deadline, got %v", remaining)
+	}
+
+	client.http.Timeout = 0
+	ctx, cancel = client.Simple().context()
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	if remaining := time.Until(deadline); remaining < defaultSimpleTimeout-time.Second {
+		t.Errorf("expected default timeout, got %v", remaining)
+	}
+}
@@ -0,0 +1,40 @@
+package qpay
+
+// TaxType is the ebarimt tax type code QPay uses in tax_type fields.
+type TaxType string
+
+// Ebarimt tax types.
+const (
+	// TaxTypeVAT is goods and services subject to VAT.
+	TaxTypeVAT TaxType = "1"
+	// TaxTypeVATExempt is goods and services exempt from VAT.
+	TaxTypeVATExempt TaxType = "2"
+	// TaxTypeZeroRated is goods and services taxed at a 0% VAT rate, such as
+	// exports.
+	TaxTypeZeroRated TaxType = "3"
+)
+
+// IsValid reports whether t is a known tax type.
+func (t TaxType) IsValid() bool {
+	switch t {
+	case TaxTypeVAT, TaxTypeVATExempt, TaxTypeZeroRated:
+		return true
+	}
+	return false
+}
+
+// IsVATable reports whether VAT is charged at the standard rate.
+func (t TaxType) IsVATable() bool {
+	return t == TaxTypeVAT
+}
+
+// IsVATExempt reports whether the receipt is exempt from VAT.
+func (t TaxType) IsVATExempt() bool {
+	return t == TaxTypeVATExempt
+}
+
+// IsZeroRated reports whether VAT applies at a 0% rate. Zero-rated receipts
+// are VAT-registered supplies, unlike exempt ones, and report differently.
+func (t TaxType) IsZeroRated() bool {
+	return t == TaxTypeZeroRated
+}
@@ -0,0 +1,53 @@
+package qpay
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaxType_Predicates(t *testing.T) {
+	tests := []struct {
+		tax                      TaxType
+		valid, vat, exempt, zero bool
+	}{
+		{TaxTypeVAT, true, true, false, false},
+		{TaxTypeVATExempt, true, false, true, false},
+		{TaxTypeZeroRated, true, false, false, true},
+		{"9", false, false, false, false},
+		{"", false, false, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.tax.IsValid(); got != tt.valid {
+			t.Errorf("%q.IsValid() = %v", tt.tax, got)
+		}
+		if got := tt.tax.IsVATable(); got != tt.vat {
+			t.Errorf("%q.IsVATable() = %v", tt.tax, got)
+		}
+		if got := tt.tax.IsVATExempt(); got != tt.exempt {
+			t.Errorf("%q.IsVATExempt() = %v", tt.tax, got)
+		}
+		if got := tt.tax.IsZeroRated(); got != tt.zero {
+			t.Errorf("%q.IsZeroRated() = %v", tt.tax, got)
+		}
+	}
+}
+
+func TestTaxType_JSON(t *testing.T) {
+	var resp EbarimtResponse
+	if err := json.Unmarshal([]byte(`{"tax_type":"2"}`), &resp); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !resp.TaxType.IsVATExempt() {
+		t.Errorf("expected VAT-exempt, got %q", resp.TaxType)
+	}
+
+	data, err := json.Marshal(CreateEbarimtInvoiceRequest{TaxType: TaxTypeVAT})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	if raw["tax_type"] != "1" {
+		t.Errorf(`expected "tax_type":"1", got %v`, raw["tax_type"])
+	}
+}
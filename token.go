@@ -0,0 +1,247 @@
+package qpay
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCachedSessions bounds the number of per-credential token sets kept for
+// context-scoped credential overrides.
+const maxCachedSessions = 32
+
+// Token expiry normalization. QPay's expires_in fields have been seen both as
+// lifetimes in seconds and as absolute Unix timestamps; values below
+// absoluteExpiryThreshold (September 2001) are taken as lifetimes. Expiries
+// are clamped to maxTokenLifetime from now so a bogus far-future value can't
+// disable refresh.
+const (
+	absoluteExpiryThreshold = 1_000_000_000
+	maxTokenLifetime        = 30 * 24 * 60 * 60
+)
+
+// normalizeExpiry converts an expires_in value to an absolute Unix time.
+func normalizeExpiry(expiresIn, now int64) int64 {
+	at := expiresIn
+	if expiresIn < absoluteExpiryThreshold {
+		at = now + expiresIn
+	}
+	if at > now+maxTokenLifetime {
+		at = now + maxTokenLifetime
+	}
+	return at
+}
+
+// AccessTokenExpiry returns when the access token expires, normalizing
+// ExpiresIn the same way the client does. A relative ExpiresIn is counted from
+// the time of the call, so call it as soon as the token is received.
+func (t *TokenResponse) AccessTokenExpiry() time.Time {
+	return time.Unix(normalizeExpiry(t.ExpiresIn, time.Now().Unix()), 0)
+}
+
+// RefreshTokenExpiry returns when the refresh token expires, normalizing
+// RefreshExpiresIn like AccessTokenExpiry.
+func (t *TokenResponse) RefreshTokenExpiry() time.Time {
+	return time.Unix(normalizeExpiry(t.RefreshExpiresIn, time.Now().Unix()), 0)
+}
+
+// NotBefore returns the not-before-policy cutoff, the Unix time before which
+// the issuer no longer accepts tokens, reporting false when it is unset ("0"
+// or empty) or not a number. The client doesn't enforce it: the cutoff
+// arrives with the token it applies to, which is always issued after it, so it
+// is only useful when compared with tokens held elsewhere.
+func (t *TokenResponse) NotBefore() (time.Time, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(t.NotBeforePolicy), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(n, 0), true
+}
+
+// validate rejects a token response without an access token or a positive
+// expiry, which would otherwise be stored and trigger re-authentication on
+// every request.
+func (t *TokenResponse) validate() error {
+	if t.AccessToken == "" {
+		return fmt.Errorf("%w: missing access_token", ErrInvalidTokenResponse)
+	}
+	if t.ExpiresIn <= 0 {
+		return fmt.Errorf("%w: expires_in must be positive, got %d", ErrInvalidTokenResponse, t.ExpiresIn)
+	}
+	return nil
+}
+
+// defaultTokenType is used in the Authorization header when QPay's token
+// response has no token_type.
+const defaultTokenType = "Bearer"
+
+// tokenState holds the token pair and expiry times for one set of credentials.
+type tokenState struct {
+	tokenType        string
+	accessToken      string
+	refreshToken     string
+	expiresAt        int64
+	refreshExpiresAt int64
+	scope            string
+	sessionState     string
+}
+
+func (s *tokenState) storeToken(token *TokenResponse, now time.Time) {
+	s.tokenType = token.TokenType
+	s.accessToken = token.AccessToken
+	s.refreshToken = token.RefreshToken
+	s.expiresAt = normalizeExpiry(token.ExpiresIn, now.Unix())
+	s.refreshExpiresAt = normalizeExpiry(token.RefreshExpiresIn, now.Unix())
+	s.scope = token.Scope
+	s.sessionState = token.SessionState
+}
+
+// authorization returns the Authorization header value for the access token,
+// using the token type QPay issued it with.
+func (s *tokenState) authorization() string {
+	tokenType := s.tokenType
+	if tokenType == "" {
+		tokenType = defaultTokenType
+	}
+	return tokenType + " " + s.accessToken
+}
+
+// WithInitialToken stores token for the Config credentials as the client is
+// built, so the first request uses it instead of authenticating, e.g. in a
+// short-lived function handed a token fetched elsewhere. Its expiry is
+// normalized as by AccessTokenExpiry, counting a relative ExpiresIn from
+// construction by the client's clock once every option has been applied, so
+// its order relative to WithClock doesn't matter. A nil token, or one without
+// an access token or positive expiry, is ignored and the client authenticates
+// as usual.
+func WithInitialToken(token *TokenResponse) Option {
+	return func(c *Client) {
+		if token == nil || token.validate() != nil {
+			return
+		}
+		initial := *token
+		c.initialToken = &initial
+	}
+}
+
+// InvalidateToken discards the cached access tokens, for the Config
+// credentials and every context-scoped session, so the next request refreshes
+// them. It is safe to call concurrently with requests.
+func (c *Client) InvalidateToken() {
+	c.mu.Lock()
+	c.tokenState.invalidate(false)
+	for _, s := range c.sessions {
+		s.invalidate(false)
+	}
+	c.mu.Unlock()
+	c.emitTokenEvent(TokenEvent{Type: TokenEventInvalidate, Time: c.now()})
+}
+
+// InvalidateRefreshToken discards the cached access and refresh tokens so the
+// next request authenticates from scratch, e.g. after rotating credentials. It
+// is safe to call concurrently with requests.
+func (c *Client) InvalidateRefreshToken() {
+	c.mu.Lock()
+	c.tokenState.invalidate(true)
+	for _, s := range c.sessions {
+		s.invalidate(true)
+	}
+	c.mu.Unlock()
+	c.emitTokenEvent(TokenEvent{Type: TokenEventInvalidateRefresh, Time: c.now()})
+}
+
+func (s *tokenState) invalidate(refresh bool) {
+	s.accessToken = ""
+	s.expiresAt = 0
+	if refresh {
+		s.refreshToken = ""
+		s.refreshExpiresAt = 0
+	}
+}
+
+// TokenScope returns the scope granted with the most recent token for the
+// Config credentials, or "" before the first authentication. Compare it with
+// the expected scope when diagnosing PERMISSION_DENIED errors.
+func (c *Client) TokenScope() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scope
+}
+
+// SessionState returns the session_state of the most recent token for the
+// Config credentials, or "" before the first authentication.
+func (c *Client) SessionState() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionState
+}
+
+// tokenPreviewChars is how many characters of the access token
+// AuthHeaderPreview shows at each end.
+const tokenPreviewChars = 4
+
+// AuthHeaderPreview returns the Authorization header the client would send
+// for the Config credentials with the access token shortened to its first and
+// last few characters, e.g. "Bearer eyJh…x9Qk", so it is safe to log when
+// checking which token is in use. Tokens too short to shorten safely are shown
+// as "…". It returns "" before the first authentication or after
+// InvalidateToken.
+func (c *Client) AuthHeaderPreview() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken == "" {
+		return ""
+	}
+	tokenType := c.tokenType
+	if tokenType == "" {
+		tokenType = defaultTokenType
+	}
+	return tokenType + " " + previewToken(c.accessToken)
+}
+
+func previewToken(token string) string {
+	if len(token) < 3*tokenPreviewChars {
+		return "…"
+	}
+	return token[:tokenPreviewChars] + "…" + token[len(token)-tokenPreviewChars:]
+}
+
+// session returns the token state for the credentials carried by ctx.
+// Calls without a credential override, or overriding with the configured
+// username, share the client's own token state. The caller must hold c.mu.
+func (c *Client) session(ctx context.Context) *tokenState {
+	creds, ok := CredentialsFromContext(ctx)
+	if !ok || creds.Username == c.config.Username {
+		return &c.tokenState
+	}
+
+	if s, ok := c.sessions[creds.Username]; ok {
+		return s
+	}
+
+	if c.sessions == nil {
+		c.sessions = make(map[string]*tokenState)
+	}
+	if len(c.sessions) >= maxCachedSessions {
+		c.evictSession()
+	}
+	s := &tokenState{}
+	c.sessions[creds.Username] = s
+	return s
+}
+
+// evictSession drops the cached session whose refresh token expires first.
+// The caller must hold c.mu.
+func (c *Client) evictSession() {
+	var victim string
+	var earliest int64
+	for username, s := range c.sessions {
+		if victim == "" || s.refreshExpiresAt < earliest {
+			victim = username
+			earliest = s.refreshExpiresAt
+		}
+	}
+	delete(c.sessions, victim)
+}
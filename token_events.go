@@ -0,0 +1,68 @@
+package qpay
+
+import (
+	"context"
+	"time"
+)
+
+// TokenEventType identifies a token lifecycle event.
+type TokenEventType string
+
+// Token lifecycle events reported to a TokenEventHook.
+const (
+	// TokenEventAuth is a full authentication with Basic Auth, from a request
+	// needing a token, GetToken, FetchToken or Ping.
+	TokenEventAuth TokenEventType = "auth"
+	// TokenEventRefresh is an access token refresh with the refresh token.
+	TokenEventRefresh TokenEventType = "refresh"
+	// TokenEventFallback follows a failed refresh when the client falls back
+	// to full authentication; Err is the refresh error.
+	TokenEventFallback TokenEventType = "fallback"
+	// TokenEventInvalidate is a call to InvalidateToken, or ValidateToken
+	// discarding a token QPay rejected; Err is then the rejection.
+	TokenEventInvalidate TokenEventType = "invalidate"
+	// TokenEventInvalidateRefresh is a call to InvalidateRefreshToken.
+	TokenEventInvalidateRefresh TokenEventType = "invalidate_refresh"
+)
+
+// TokenEvent describes one token lifecycle event. It never carries token
+// values or passwords, so it is safe to write to an audit log.
+type TokenEvent struct {
+	Type TokenEventType
+	Time time.Time
+	// Username is the QPay username the token belongs to: the Config's, or
+	// the one set with WithCredentials. It is empty for InvalidateToken and
+	// InvalidateRefreshToken, which cover every cached session.
+	Username string
+	// Err is nil when the authentication or refresh succeeded.
+	Err error
+}
+
+// TokenEventHook is called synchronously with each token lifecycle event, from
+// the goroutine making the request. It must not block.
+type TokenEventHook func(TokenEvent)
+
+// WithTokenEventHook registers a hook that sees every token authentication,
+// refresh, fallback and invalidation, e.g. for a credential audit trail.
+// Hooks run in registration order.
+func WithTokenEventHook(hook TokenEventHook) Option {
+	return func(c *Client) {
+		c.tokenHooks = append(c.tokenHooks, hook)
+	}
+}
+
+// tokenEvent reports an event for the credentials carried by ctx. It must be
+// called without holding c.mu, so a hook may use the client.
+func (c *Client) tokenEvent(ctx context.Context, typ TokenEventType, err error) {
+	if len(c.tokenHooks) == 0 {
+		return
+	}
+	username, _ := c.basicAuth(ctx)
+	c.emitTokenEvent(TokenEvent{Type: typ, Time: c.now(), Username: username, Err: err})
+}
+
+func (c *Client) emitTokenEvent(ev TokenEvent) {
+	for _, hook := range c.tokenHooks {
+		hook(ev)
+	}
+}
@@ -0,0 +1,107 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTokenEventHook_Lifecycle(t *testing.T) {
+	var tokens, refreshes int
+	server := newCountingAuthServer(t, &tokens, &refreshes)
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []TokenEvent
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithClock(func() time.Time { return now }),
+		WithTokenEventHook(func(ev TokenEvent) { events = append(events, ev) }))
+	ctx := context.Background()
+
+	client.ensureToken(ctx)
+	client.InvalidateToken()
+	client.ensureToken(ctx)
+	client.InvalidateRefreshToken()
+
+	var types []TokenEventType
+	for _, ev := range events {
+		types = append(types, ev.Type)
+		if ev.Err != nil {
+			t.Errorf("%s: unexpected error %v", ev.Type, ev.Err)
+		}
+		if !ev.Time.Equal(now) {
+			t.Errorf("%s: expected time from the client clock, got %v", ev.Type, ev.Time)
+		}
+	}
+	want := []TokenEventType{TokenEventAuth, TokenEventInvalidate, TokenEventRefresh, TokenEventInvalidateRefresh}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	if events[0].Username != "user" || events[1].Username != "" {
+		t.Errorf("unexpected usernames %q, %q", events[0].Username, events[1].Username)
+	}
+}
+
+func TestTokenEventHook_Fallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/auth/refresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": ErrAuthenticationFailed})
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 3600, RefreshExpiresIn: 7200})
+	}))
+	defer server.Close()
+
+	var events []TokenEvent
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithTokenEventHook(func(ev TokenEvent) { events = append(events, ev) }))
+	ctx := WithCredentials(context.Background(), Credentials{Username: "tenant", Password: "secret"})
+
+	client.ensureToken(ctx)
+	events = nil
+	client.InvalidateToken()
+	if err := client.ensureToken(ctx); err != nil {
+		t.Fatalf("ensureToken failed: %v", err)
+	}
+
+	var types []TokenEventType
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	want := []TokenEventType{TokenEventInvalidate, TokenEventRefresh, TokenEventFallback, TokenEventAuth}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	for _, i := range []int{1, 2} {
+		if qErr, ok := IsQPayError(events[i].Err); !ok || qErr.Code != ErrAuthenticationFailed {
+			t.Errorf("%s: expected the refresh error, got %v", events[i].Type, events[i].Err)
+		}
+	}
+	if events[3].Err != nil || events[3].Username != "tenant" {
+		t.Errorf("expected a successful auth for tenant, got %+v", events[3])
+	}
+}
+
+func TestTokenEventHook_AuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": ErrAuthenticationFailed})
+	}))
+	defer server.Close()
+
+	var events []TokenEvent
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithTokenEventHook(func(ev TokenEvent) { events = append(events, ev) }))
+
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(events) != 1 || events[0].Type != TokenEventAuth || events[0].Err == nil {
+		t.Errorf("expected one failed auth event, got %+v", events)
+	}
+}
@@ -0,0 +1,353 @@
+package qpay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSession_EvictsEarliestExpiring(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn", Username: "default"})
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	for i := 0; i < maxCachedSessions; i++ {
+		ctx := WithCredentials(context.Background(), Credentials{Username: fmt.Sprintf("tenant-%d", i)})
+		client.session(ctx).refreshExpiresAt = int64(1000 + i)
+	}
+	// tenant-5 expires first
+	client.sessions["tenant-5"].refreshExpiresAt = 1
+
+	ctx := WithCredentials(context.Background(), Credentials{Username: "newcomer"})
+	client.session(ctx)
+
+	if len(client.sessions) != maxCachedSessions {
+		t.Errorf("expected %d sessions, got %d", maxCachedSessions, len(client.sessions))
+	}
+	if _, ok := client.sessions["tenant-5"]; ok {
+		t.Error("expected tenant-5 to be evicted")
+	}
+	if _, ok := client.sessions["newcomer"]; !ok {
+		t.Error("expected newcomer session to be cached")
+	}
+}
+
+func TestSession_DefaultCredentials(t *testing.T) {
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn", Username: "default"})
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.session(context.Background()) != &client.tokenState {
+		t.Error("expected default session for context without credentials")
+	}
+	if client.sessions != nil {
+		t.Error("expected no sessions to be allocated")
+	}
+}
+
+func TestTokenScopeAndSessionState(t *testing.T) {
+	client := NewClient(&Config{Username: "user"})
+	if client.TokenScope() != "" || client.SessionState() != "" {
+		t.Error("expected empty scope and session state before authentication")
+	}
+
+	client.storeToken(&TokenResponse{
+		AccessToken:  "a",
+		Scope:        "profile email",
+		SessionState: "sess-1",
+	}, time.Now())
+	if client.TokenScope() != "profile email" {
+		t.Errorf("expected scope 'profile email', got %q", client.TokenScope())
+	}
+	if client.SessionState() != "sess-1" {
+		t.Errorf("expected session state 'sess-1', got %q", client.SessionState())
+	}
+
+	// A refresh that carries new values replaces them.
+	client.storeToken(&TokenResponse{AccessToken: "b", Scope: "profile"}, time.Now())
+	if client.TokenScope() != "profile" || client.SessionState() != "" {
+		t.Errorf("expected refreshed values, got %q / %q", client.TokenScope(), client.SessionState())
+	}
+}
+
+func TestAuthHeaderPreview(t *testing.T) {
+	client := NewClient(&Config{Username: "user"})
+	if got := client.AuthHeaderPreview(); got != "" {
+		t.Errorf("expected empty preview before authentication, got %q", got)
+	}
+
+	client.storeToken(&TokenResponse{AccessToken: "eyJhbGciOiJSUzI1NiJ9.payload.x9Qk"}, time.Now())
+	if got := client.AuthHeaderPreview(); got != "Bearer eyJh…x9Qk" {
+		t.Errorf("unexpected preview %q", got)
+	}
+
+	client.storeToken(&TokenResponse{TokenType: "bearer", AccessToken: "short"}, time.Now())
+	if got := client.AuthHeaderPreview(); got != "bearer …" {
+		t.Errorf("expected short token hidden, got %q", got)
+	}
+
+	client.InvalidateToken()
+	if got := client.AuthHeaderPreview(); got != "" {
+		t.Errorf("expected empty preview after InvalidateToken, got %q", got)
+	}
+}
+
+func TestTokenScope_SetByGetToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:  "access",
+			ExpiresIn:    time.Now().Unix() + 3600,
+			Scope:        "qpay",
+			SessionState: "abc",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+	if _, err := client.GetToken(context.Background()); err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+	if client.TokenScope() != "qpay" || client.SessionState() != "abc" {
+		t.Errorf("expected scope 'qpay' and session 'abc', got %q / %q", client.TokenScope(), client.SessionState())
+	}
+}
+
+func TestNormalizeExpiry(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		expiresIn int64
+		expected  int64
+	}{
+		{"relative seconds", 3600, now + 3600},
+		{"absolute timestamp", now + 3600, now + 3600},
+		{"absolute in the past", now - 100, now - 100},
+		{"year 9999 clamped", 253402300799, now + maxTokenLifetime},
+		{"huge relative clamped", absoluteExpiryThreshold - 1, now + maxTokenLifetime},
+		{"zero expires now", 0, now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeExpiry(tt.expiresIn, now); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStoreToken_NormalizesExpiry(t *testing.T) {
+	var s tokenState
+	before := time.Now().Unix()
+	s.storeToken(&TokenResponse{ExpiresIn: 3600, RefreshExpiresIn: 253402300799}, time.Now())
+	after := time.Now().Unix()
+
+	if s.expiresAt < before+3600 || s.expiresAt > after+3600 {
+		t.Errorf("expected relative expiry near now+3600, got %d", s.expiresAt)
+	}
+	if s.refreshExpiresAt > after+maxTokenLifetime {
+		t.Errorf("expected refresh expiry clamped to %d, got %d", after+maxTokenLifetime, s.refreshExpiresAt)
+	}
+}
+
+// newCountingAuthServer serves token and refresh endpoints, counting calls to each.
+func newCountingAuthServer(t *testing.T, tokens, refreshes *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/auth/token":
+			*tokens++
+		case "/v2/auth/refresh":
+			*refreshes++
+		default:
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:      "access",
+			RefreshToken:     "refresh",
+			ExpiresIn:        3600,
+			RefreshExpiresIn: 7200,
+		})
+	}))
+}
+
+func TestInvalidateToken(t *testing.T) {
+	var tokens, refreshes int
+	server := newCountingAuthServer(t, &tokens, &refreshes)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+	ctx := context.Background()
+	tenant := WithCredentials(ctx, Credentials{Username: "tenant", Password: "secret"})
+
+	client.ensureToken(ctx)
+	client.ensureToken(tenant)
+	client.InvalidateToken()
+
+	if client.accessToken != "" || client.refreshToken == "" {
+		t.Error("expected access token cleared and refresh token kept")
+	}
+	client.ensureToken(ctx)
+	client.ensureToken(tenant)
+
+	if tokens != 2 || refreshes != 2 {
+		t.Errorf("expected 2 token and 2 refresh calls, got %d and %d", tokens, refreshes)
+	}
+}
+
+func TestInvalidateRefreshToken(t *testing.T) {
+	var tokens, refreshes int
+	server := newCountingAuthServer(t, &tokens, &refreshes)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client())
+	ctx := context.Background()
+
+	client.ensureToken(ctx)
+	client.InvalidateRefreshToken()
+	client.ensureToken(ctx)
+
+	if tokens != 2 || refreshes != 0 {
+		t.Errorf("expected full re-authentication, got %d token and %d refresh calls", tokens, refreshes)
+	}
+}
+
+func TestWithClock_DrivesTokenExpiry(t *testing.T) {
+	var tokens, refreshes int
+	server := newCountingAuthServer(t, &tokens, &refreshes)
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := NewClientWithHTTPClient(&Config{BaseURL: server.URL, Username: "user", Password: "pass"}, server.Client(),
+		WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	client.ensureToken(ctx)
+	if client.expiresAt != now.Unix()+3600 {
+		t.Errorf("expected expiry from injected clock, got %d", client.expiresAt)
+	}
+
+	now = now.Add(30 * time.Minute)
+	client.ensureToken(ctx)
+	if tokens != 1 || refreshes != 0 {
+		t.Errorf("expected cached token, got %d token and %d refresh calls", tokens, refreshes)
+	}
+
+	now = now.Add(time.Hour)
+	client.ensureToken(ctx)
+	if refreshes != 1 {
+		t.Errorf("expected a refresh after the access token expired, got %d", refreshes)
+	}
+
+	now = now.Add(3 * time.Hour)
+	client.ensureToken(ctx)
+	if tokens != 2 {
+		t.Errorf("expected full re-authentication after the refresh token expired, got %d token calls", tokens)
+	}
+}
+
+func TestTokenResponse_Expiry(t *testing.T) {
+	now := time.Now().Unix()
+	token := &TokenResponse{ExpiresIn: 3600, RefreshExpiresIn: now + 7200}
+
+	access := token.AccessTokenExpiry().Unix()
+	if access < now+3600 || access > now+3601 {
+		t.Errorf("expected relative expiry near now+3600, got %d", access)
+	}
+	if got := token.RefreshTokenExpiry().Unix(); got != now+7200 {
+		t.Errorf("expected absolute refresh expiry %d, got %d", now+7200, got)
+	}
+
+	// The client stores the same instant.
+	var s tokenState
+	s.storeToken(token, time.Unix(now, 0))
+	if s.refreshExpiresAt != token.RefreshTokenExpiry().Unix() {
+		t.Errorf("expected client and accessor to agree, got %d and %d", s.refreshExpiresAt, token.RefreshTokenExpiry().Unix())
+	}
+
+	far := &TokenResponse{ExpiresIn: 253402300799}
+	if got := far.AccessTokenExpiry().Unix(); got > time.Now().Unix()+maxTokenLifetime {
+		t.Errorf("expected far-future expiry clamped, got %d", got)
+	}
+}
+
+func TestWithInitialToken_SkipsAuthentication(t *testing.T) {
+	var authCalls int
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/auth/token" {
+			authCalls++
+			json.NewEncoder(w).Encode(TokenResponse{AccessToken: "fetched", ExpiresIn: 3600})
+			return
+		}
+		sent = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(PaymentDetail{PaymentID: "pay-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{BaseURL: server.URL, Username: "u", Password: "p"},
+		WithInitialToken(&TokenResponse{AccessToken: "prefetched", ExpiresIn: 3600}))
+	if _, err := client.GetPayment(context.Background(), "pay-1"); err != nil {
+		t.Fatalf("GetPayment failed: %v", err)
+	}
+	if authCalls != 0 || sent != "Bearer prefetched" {
+		t.Errorf("expected the initial token without authenticating, got %q after %d auth calls", sent, authCalls)
+	}
+}
+
+func TestWithInitialToken_IgnoresUnusable(t *testing.T) {
+	for name, token := range map[string]*TokenResponse{
+		"nil":       nil,
+		"no access": {ExpiresIn: 3600},
+		"no expiry": {AccessToken: "tok"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(&Config{BaseURL: "https://api.qpay.mn"}, WithInitialToken(token))
+			if client.AuthHeaderPreview() != "" {
+				t.Errorf("expected no stored token, got %q", client.AuthHeaderPreview())
+			}
+		})
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := NewClient(&Config{BaseURL: "https://api.qpay.mn"},
+		WithClock(func() time.Time { return now }),
+		WithInitialToken(&TokenResponse{AccessToken: "tok", ExpiresIn: 600}))
+	if want := now.Add(600 * time.Second).Unix(); client.expiresAt != want {
+		t.Errorf("expected relative expiry from the client clock, got %d, want %d", client.expiresAt, want)
+	}
+
+	client = NewClient(&Config{BaseURL: "https://api.qpay.mn"},
+		WithInitialToken(&TokenResponse{AccessToken: "tok", ExpiresIn: 600}),
+		WithClock(func() time.Time { return now }))
+	if want := now.Add(600 * time.Second).Unix(); client.expiresAt != want {
+		t.Errorf("expected the clock to apply regardless of option order, got %d, want %d", client.expiresAt, want)
+	}
+}
+
+func TestTokenResponse_NotBefore(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   int64
+		ok     bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"soon", 0, false},
+		{" 1700000000 ", 1700000000, true},
+	}
+	for _, tt := range tests {
+		got, ok := (&TokenResponse{NotBeforePolicy: tt.policy}).NotBefore()
+		if ok != tt.ok || (ok && got.Unix() != tt.want) {
+			t.Errorf("NotBefore(%q) = %v, %v; want %d, %v", tt.policy, got, ok, tt.want, tt.ok)
+		}
+	}
+}
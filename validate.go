@@ -0,0 +1,264 @@
+package qpay
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// AmountTolerance is the largest difference ValidateAmounts accepts between a
+// summed amount and the invoice Amount.
+const AmountTolerance = 0.01
+
+// Validate checks the request for errors QPay would otherwise reject remotely.
+// A receiver email, if set, must be an email address. Each transaction account
+// needs a bank code and an account number or IBAN, and at most one account per
+// transaction may be the default; QPay reports these as BANK_ACCOUNT_NOTFOUND
+// or ACCOUNT_SELECTION_INVALID. A nil request is rejected. CreateInvoice calls
+// it before sending the request.
+func (r *CreateInvoiceRequest) Validate() error {
+	if r == nil {
+		return &ValidationError{Field: "request", Message: "required"}
+	}
+	if d := r.InvoiceReceiverData; d != nil && d.Email != "" && !isEmailAddress(strings.TrimSpace(d.Email)) {
+		return &ValidationError{Field: "invoice_receiver_data.email", Message: fmt.Sprintf("%q is not an email address", d.Email)}
+	}
+	for i, tx := range r.Transactions {
+		defaultAt := -1
+		for j, acc := range tx.Accounts {
+			field := fmt.Sprintf("transactions[%d].accounts[%d]", i, j)
+			if strings.TrimSpace(acc.AccountBankCode) == "" {
+				return &ValidationError{Field: field + ".account_bank_code", Message: "required"}
+			}
+			if strings.TrimSpace(acc.AccountNumber) == "" && strings.TrimSpace(acc.IBANNumber) == "" {
+				return &ValidationError{Field: field + ".account_number", Message: "account number or IBAN required"}
+			}
+			if acc.IsDefault {
+				if defaultAt >= 0 {
+					return &ValidationError{
+						Field:   field + ".is_default",
+						Message: fmt.Sprintf("accounts[%d] is already the default", defaultAt),
+					}
+				}
+				defaultAt = j
+			}
+			if acc.AccountCurrency == "" {
+				continue
+			}
+			if _, err := ParseCurrency(acc.AccountCurrency); err != nil {
+				return &ValidationError{
+					Field:   field + ".account_currency",
+					Message: fmt.Sprintf("unsupported currency %q", acc.AccountCurrency),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RequireReceiverData checks that InvoiceReceiverData has the fields the
+// invoice code requires, which only QPay knows; pass what your invoice code is
+// configured to need. A missing field is reported with the code QPay would
+// return, such as INVOICE_RECEIVER_DATA_EMAIL_REQUIRED, in the message. An
+// address counts as present when any of its fields is set.
+func (r *CreateInvoiceRequest) RequireReceiverData(email, phone, address bool) error {
+	d := r.InvoiceReceiverData
+	if d == nil {
+		if !email && !phone && !address {
+			return nil
+		}
+		return &ValidationError{Field: "invoice_receiver_data", Message: "required (" + ErrInvoiceReceiverDataRequired + ")"}
+	}
+	switch {
+	case email && strings.TrimSpace(d.Email) == "":
+		return &ValidationError{Field: "invoice_receiver_data.email", Message: "required (" + ErrInvoiceReceiverDataEmailReq + ")"}
+	case phone && strings.TrimSpace(d.Phone) == "":
+		return &ValidationError{Field: "invoice_receiver_data.phone", Message: "required (" + ErrInvoiceReceiverDataPhoneReq + ")"}
+	case address && (d.Address == nil || *d.Address == Address{}):
+		return &ValidationError{Field: "invoice_receiver_data.address", Message: "required (" + ErrInvoiceReceiverDataAddrReq + ")"}
+	}
+	return nil
+}
+
+// ValidateAmounts checks that the invoice Amount matches the sum of
+// Transaction amounts and, separately, the sum of line totals, each within
+// AmountTolerance. A line total is LineQuantity*LineUnitPrice minus discounts
+// plus surcharges; Taxes are taken as included in the unit price. Empty
+// Transactions or Lines are not checked. CreateInvoice calls it when the client
+// was built with WithAmountCheck.
+func (r *CreateInvoiceRequest) ValidateAmounts() error {
+	if len(r.Transactions) > 0 {
+		var sum float64
+		for i, tx := range r.Transactions {
+			v, err := validateAmount(fmt.Sprintf("transactions[%d].amount", i), tx.Amount)
+			if err != nil {
+				return err
+			}
+			sum += v
+		}
+		if math.Abs(sum-r.Amount) > AmountTolerance {
+			return &ValidationError{Field: "transactions", Message: fmt.Sprintf("amounts sum to %.2f, invoice amount is %.2f", sum, r.Amount)}
+		}
+	}
+
+	if len(r.Lines) > 0 {
+		var sum float64
+		for i, line := range r.Lines {
+			total, err := line.total(i)
+			if err != nil {
+				return err
+			}
+			sum += total
+		}
+		if math.Abs(sum-r.Amount) > AmountTolerance {
+			return &ValidationError{Field: "lines", Message: fmt.Sprintf("line totals sum to %.2f, invoice amount is %.2f", sum, r.Amount)}
+		}
+	}
+	return nil
+}
+
+// BalanceTransactions brings Amount, Lines and Transactions into agreement
+// for split-settlement invoices. A zero Amount is set to the line total.
+// Transactions with an empty Amount share what the others leave of the invoice
+// Amount, split evenly to the cent with any odd cents going to the first ones;
+// if every transaction has an amount they must add up to the invoice Amount.
+// Lines, if any, must also add up to it, each within AmountTolerance. A
+// mismatch is reported as a *ValidationError naming the totals, and the
+// request is left unchanged.
+func (r *CreateInvoiceRequest) BalanceTransactions() error {
+	amount := r.Amount
+	if len(r.Lines) > 0 {
+		var sum float64
+		for i, line := range r.Lines {
+			total, err := line.total(i)
+			if err != nil {
+				return err
+			}
+			sum += total
+		}
+		if amount == 0 {
+			amount = sum
+		}
+		if math.Abs(sum-amount) > AmountTolerance {
+			return &ValidationError{Field: "lines", Message: fmt.Sprintf("line totals sum to %.2f, invoice amount is %.2f", sum, amount)}
+		}
+	}
+
+	var fixed float64
+	var open []int
+	for i, tx := range r.Transactions {
+		if strings.TrimSpace(tx.Amount) == "" {
+			open = append(open, i)
+			continue
+		}
+		v, err := validateAmount(fmt.Sprintf("transactions[%d].amount", i), tx.Amount)
+		if err != nil {
+			return err
+		}
+		fixed += v
+	}
+
+	remaining := amount - fixed
+	switch {
+	case len(open) == 0 && len(r.Transactions) > 0 && math.Abs(remaining) > AmountTolerance:
+		return &ValidationError{Field: "transactions", Message: fmt.Sprintf("amounts sum to %.2f, invoice amount is %.2f", fixed, amount)}
+	case len(open) > 0 && remaining < float64(len(open))/100:
+		return &ValidationError{Field: "transactions", Message: fmt.Sprintf("amounts sum to %.2f, leaving %.2f of %.2f for %d transactions without an amount", fixed, remaining, amount, len(open))}
+	}
+
+	r.Amount = amount
+	cents := int64(math.Round(remaining * 100))
+	for k, i := range open {
+		share := cents / int64(len(open))
+		if int64(k) < cents%int64(len(open)) {
+			share++
+		}
+		r.Transactions[i].Amount = strconv.FormatFloat(float64(share)/100, 'f', -1, 64)
+	}
+	return nil
+}
+
+// total returns the line's quantity times unit price, less discounts, plus
+// surcharges. i is the line's index, used in error fields.
+func (l InvoiceLine) total(i int) (float64, error) {
+	qty, err := validateAmount(fmt.Sprintf("lines[%d].line_quantity", i), l.LineQuantity)
+	if err != nil {
+		return 0, err
+	}
+	price, err := validateAmount(fmt.Sprintf("lines[%d].line_unit_price", i), l.LineUnitPrice)
+	if err != nil {
+		return 0, err
+	}
+
+	total := qty * price
+	for _, d := range l.Discounts {
+		total -= d.Amount
+	}
+	for _, s := range l.Surcharges {
+		total += s.Amount
+	}
+	return total, nil
+}
+
+// validateAmount parses a string amount, reporting failure as a
+// *ValidationError for field.
+func validateAmount(field, s string) (float64, error) {
+	v, err := parseAmount(field, s)
+	if err != nil {
+		return 0, &ValidationError{Field: field, Message: fmt.Sprintf("not a number: %q", s)}
+	}
+	return v, nil
+}
+
+// Validate checks EbarimtReceiver against EbarimtReceiverType. Organization
+// receipts need a register number (7 digits) or TIN (11 to 14 digits). Citizen
+// receipts take an empty receiver, an 8-digit phone number (optionally with a
+// +976 prefix) or an email address. Other receiver types are not checked.
+// A nil request is rejected. CreateEbarimt calls it before sending the
+// request.
+func (r *CreateEbarimtRequest) Validate() error {
+	if r == nil {
+		return &ValidationError{Field: "request", Message: "required"}
+	}
+	receiver := strings.TrimSpace(r.EbarimtReceiver)
+	switch r.EbarimtReceiverType {
+	case EbarimtReceiverOrganization:
+		if receiver == "" {
+			return &ValidationError{Field: "ebarimt_receiver", Message: "required for organization receipts"}
+		}
+		if n := len(receiver); !isDigits(receiver) || (n != 7 && (n < 11 || n > 14)) {
+			return &ValidationError{Field: "ebarimt_receiver", Message: fmt.Sprintf("%q is not a register number or TIN", r.EbarimtReceiver)}
+		}
+	case EbarimtReceiverCitizen:
+		if receiver == "" || isPhoneNumber(receiver) {
+			return nil
+		}
+		if !isEmailAddress(receiver) {
+			return &ValidationError{Field: "ebarimt_receiver", Message: fmt.Sprintf("%q is not a phone number or email address", r.EbarimtReceiver)}
+		}
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isEmailAddress reports whether s is a bare address such as a@b.mn, without
+// a display name or angle brackets.
+func isEmailAddress(s string) bool {
+	_, err := mail.ParseAddress(s)
+	return err == nil && strings.Contains(s, "@") && !strings.ContainsAny(s, " <>")
+}
+
+func isPhoneNumber(s string) bool {
+	s = strings.TrimPrefix(s, "+976")
+	return len(s) == 8 && isDigits(s)
+}
@@ -0,0 +1,396 @@
+package qpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateInvoiceRequest_Validate_Currency(t *testing.T) {
+	req := &CreateInvoiceRequest{
+		Amount: 1000,
+		Transactions: []Transaction{
+			{Amount: "1000", Accounts: []Account{
+				{AccountBankCode: "050000", AccountNumber: "5000123456", AccountCurrency: "MNT"},
+				{AccountBankCode: "040000", IBANNumber: "MN580004000000123456", AccountCurrency: "XYZ"},
+			}},
+		},
+	}
+
+	err := req.Validate()
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if vErr.Field != "transactions[0].accounts[1].account_currency" {
+		t.Errorf("unexpected field: %q", vErr.Field)
+	}
+	if !strings.Contains(err.Error(), "XYZ") {
+		t.Errorf("expected error to mention currency, got %q", err.Error())
+	}
+
+	req.Transactions[0].Accounts[1].AccountCurrency = "mnt"
+	if err := req.Validate(); err != nil {
+		t.Errorf("expected lower-case currency to pass, got %v", err)
+	}
+}
+
+func TestCreateInvoiceRequest_Validate_Accounts(t *testing.T) {
+	valid := Account{AccountBankCode: "050000", AccountNumber: "5000123456"}
+	tests := []struct {
+		name     string
+		accounts []Account
+		field    string
+	}{
+		{"valid", []Account{valid, {AccountBankCode: "040000", IBANNumber: "MN580004000000123456", IsDefault: true}}, ""},
+		{"missing bank code", []Account{valid, {AccountNumber: "123"}}, "transactions[0].accounts[1].account_bank_code"},
+		{"missing number and IBAN", []Account{{AccountBankCode: "050000", AccountName: "Shop"}}, "transactions[0].accounts[0].account_number"},
+		{"two defaults", []Account{
+			{AccountBankCode: "050000", AccountNumber: "1", IsDefault: true},
+			valid,
+			{AccountBankCode: "040000", AccountNumber: "2", IsDefault: true},
+		}, "transactions[0].accounts[2].is_default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateInvoiceRequest{Transactions: []Transaction{{Amount: "1000", Accounts: tt.accounts}}}
+			err := req.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+			}
+			if vErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, vErr.Field)
+			}
+		})
+	}
+}
+
+func TestCreateInvoiceRequest_Validate_ReceiverEmail(t *testing.T) {
+	req := &CreateInvoiceRequest{InvoiceReceiverData: &InvoiceReceiverData{Email: "Bat <bat@example.mn>"}}
+	var vErr *ValidationError
+	if err := req.Validate(); !errors.As(err, &vErr) || vErr.Field != "invoice_receiver_data.email" {
+		t.Fatalf("expected receiver email error, got %v", err)
+	}
+	req.InvoiceReceiverData.Email = "bat@example.mn"
+	if err := req.Validate(); err != nil {
+		t.Errorf("expected valid email to pass, got %v", err)
+	}
+}
+
+func TestCreateInvoiceRequest_RequireReceiverData(t *testing.T) {
+	full := &InvoiceReceiverData{Email: "bat@example.mn", Phone: "99112233", Address: &Address{City: "Ulaanbaatar"}}
+	tests := []struct {
+		name                  string
+		data                  *InvoiceReceiverData
+		email, phone, address bool
+		field, code           string
+	}{
+		{"nothing required", nil, false, false, false, "", ""},
+		{"all present", full, true, true, true, "", ""},
+		{"no data", nil, false, true, false, "invoice_receiver_data", ErrInvoiceReceiverDataRequired},
+		{"no email", &InvoiceReceiverData{Phone: "99112233"}, true, true, false, "invoice_receiver_data.email", ErrInvoiceReceiverDataEmailReq},
+		{"blank phone", &InvoiceReceiverData{Phone: " "}, false, true, false, "invoice_receiver_data.phone", ErrInvoiceReceiverDataPhoneReq},
+		{"empty address", &InvoiceReceiverData{Address: &Address{}}, false, false, true, "invoice_receiver_data.address", ErrInvoiceReceiverDataAddrReq},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateInvoiceRequest{InvoiceReceiverData: tt.data}
+			err := req.RequireReceiverData(tt.email, tt.phone, tt.address)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) || vErr.Field != tt.field {
+				t.Fatalf("expected %s error, got %v", tt.field, err)
+			}
+			if !strings.Contains(vErr.Message, tt.code) {
+				t.Errorf("expected message to name %s, got %q", tt.code, vErr.Message)
+			}
+		})
+	}
+}
+
+func TestCreateInvoice_ValidationErrorSkipsRequest(t *testing.T) {
+	called := false
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer server.Close()
+
+	_, err := client.CreateInvoice(context.Background(), &CreateInvoiceRequest{
+		Transactions: []Transaction{{Accounts: []Account{{AccountCurrency: "BAD"}}}},
+	})
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if called {
+		t.Error("request should not reach the server")
+	}
+
+	for name, create := range map[string]func(context.Context, *CreateInvoiceRequest) (*InvoiceResponse, error){
+		"CreateInvoice":      client.CreateInvoice,
+		"CreateOrGetInvoice": client.CreateOrGetInvoice,
+	} {
+		if _, err := create(context.Background(), nil); !errors.As(err, &vErr) || vErr.Field != "request" {
+			t.Errorf("%s: expected a request ValidationError for nil, got %v", name, err)
+		}
+	}
+	if called {
+		t.Error("a nil request should not reach the server")
+	}
+}
+
+func TestCreateInvoiceRequest_ValidateAmounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   CreateInvoiceRequest
+		field string
+	}{
+		{
+			name: "no breakdown",
+			req:  CreateInvoiceRequest{Amount: 1000},
+		},
+		{
+			name: "transactions match",
+			req:  CreateInvoiceRequest{Amount: 1500, Transactions: []Transaction{{Amount: "1000.005"}, {Amount: " 500 "}}},
+		},
+		{
+			name:  "transactions mismatch",
+			req:   CreateInvoiceRequest{Amount: 1500, Transactions: []Transaction{{Amount: "1000"}, {Amount: "400"}}},
+			field: "transactions",
+		},
+		{
+			name:  "transaction amount malformed",
+			req:   CreateInvoiceRequest{Amount: 1000, Transactions: []Transaction{{Amount: "1,000"}}},
+			field: "transactions[0].amount",
+		},
+		{
+			name: "lines match with discount and surcharge",
+			req: CreateInvoiceRequest{Amount: 2050, Lines: []InvoiceLine{
+				{LineQuantity: "2", LineUnitPrice: "500", Discounts: []TaxEntry{{Amount: 100}}},
+				{LineQuantity: "1", LineUnitPrice: "1000", Surcharges: []TaxEntry{{Amount: 150}}, Taxes: []TaxEntry{{Amount: 90}}},
+			}},
+		},
+		{
+			name:  "lines mismatch",
+			req:   CreateInvoiceRequest{Amount: 1000, Lines: []InvoiceLine{{LineQuantity: "3", LineUnitPrice: "300"}}},
+			field: "lines",
+		},
+		{
+			name:  "line price malformed",
+			req:   CreateInvoiceRequest{Amount: 1000, Lines: []InvoiceLine{{LineQuantity: "1", LineUnitPrice: "1000"}, {LineQuantity: "1", LineUnitPrice: "x"}}},
+			field: "lines[1].line_unit_price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.ValidateAmounts()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+			}
+			if vErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, vErr.Field)
+			}
+		})
+	}
+}
+
+func TestCreateInvoiceRequest_BalanceTransactions(t *testing.T) {
+	lines := []InvoiceLine{{LineQuantity: "2", LineUnitPrice: "50"}}
+	tests := []struct {
+		name    string
+		req     CreateInvoiceRequest
+		amount  float64
+		amounts []string
+		field   string
+	}{
+		{
+			name:    "amount from lines, split evenly",
+			req:     CreateInvoiceRequest{Lines: lines, Transactions: []Transaction{{}, {}, {}}},
+			amount:  100,
+			amounts: []string{"33.34", "33.33", "33.33"},
+		},
+		{
+			name:    "remainder after fixed amounts",
+			req:     CreateInvoiceRequest{Amount: 100, Transactions: []Transaction{{Amount: "70"}, {}}},
+			amount:  100,
+			amounts: []string{"70", "30"},
+		},
+		{
+			name:    "all set and matching",
+			req:     CreateInvoiceRequest{Amount: 100, Lines: lines, Transactions: []Transaction{{Amount: "60"}, {Amount: "40"}}},
+			amount:  100,
+			amounts: []string{"60", "40"},
+		},
+		{
+			name:  "all set and mismatched",
+			req:   CreateInvoiceRequest{Amount: 100, Transactions: []Transaction{{Amount: "60"}, {Amount: "30"}}},
+			field: "transactions",
+		},
+		{
+			name:  "nothing left to distribute",
+			req:   CreateInvoiceRequest{Amount: 100, Transactions: []Transaction{{Amount: "100"}, {}}},
+			field: "transactions",
+		},
+		{
+			name:  "lines mismatch",
+			req:   CreateInvoiceRequest{Amount: 90, Lines: lines, Transactions: []Transaction{{}}},
+			field: "lines",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := append([]Transaction(nil), tt.req.Transactions...)
+			err := tt.req.BalanceTransactions()
+			if tt.field != "" {
+				var vErr *ValidationError
+				if !errors.As(err, &vErr) || vErr.Field != tt.field {
+					t.Fatalf("expected *ValidationError for %s, got %v", tt.field, err)
+				}
+				for i := range before {
+					if tt.req.Transactions[i].Amount != before[i].Amount {
+						t.Errorf("transactions[%d] changed on error", i)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BalanceTransactions failed: %v", err)
+			}
+			if tt.req.Amount != tt.amount {
+				t.Errorf("expected amount %v, got %v", tt.amount, tt.req.Amount)
+			}
+			for i, want := range tt.amounts {
+				if got := tt.req.Transactions[i].Amount; got != want {
+					t.Errorf("transactions[%d]: expected %q, got %q", i, want, got)
+				}
+			}
+			if err := tt.req.ValidateAmounts(); err != nil {
+				t.Errorf("expected balanced amounts, got %v", err)
+			}
+		})
+	}
+}
+func TestCreateInvoice_WithAmountCheck(t *testing.T) {
+	called := false
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer server.Close()
+
+	req := &CreateInvoiceRequest{
+		SenderInvoiceNo: "ORD-1",
+		Amount:          1000,
+		Transactions:    []Transaction{{Amount: "900"}},
+	}
+
+	// Without the option the mismatch is left for QPay to judge.
+	client.CreateInvoice(context.Background(), req)
+	if !called {
+		t.Fatal("expected request without WithAmountCheck")
+	}
+
+	called = false
+	WithAmountCheck()(client)
+	_, err := client.CreateInvoice(context.Background(), req)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || vErr.Field != "transactions" {
+		t.Fatalf("expected transactions ValidationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "900.00") {
+		t.Errorf("expected error to report the discrepancy, got %q", err.Error())
+	}
+	if called {
+		t.Error("expected no request after a failed amount check")
+	}
+}
+
+func TestCreateEbarimtRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		receiverType string
+		receiver     string
+		valid        bool
+	}{
+		{"org register number", EbarimtReceiverOrganization, "1234567", true},
+		{"org TIN", EbarimtReceiverOrganization, "12345678901", true},
+		{"org missing", EbarimtReceiverOrganization, "", false},
+		{"org wrong length", EbarimtReceiverOrganization, "123456", false},
+		{"org letters", EbarimtReceiverOrganization, "12345AB", false},
+		{"citizen empty", EbarimtReceiverCitizen, "", true},
+		{"citizen phone", EbarimtReceiverCitizen, "99112233", true},
+		{"citizen phone with prefix", EbarimtReceiverCitizen, "+97699112233", true},
+		{"citizen email", EbarimtReceiverCitizen, "bat@example.mn", true},
+		{"citizen short phone", EbarimtReceiverCitizen, "991122", false},
+		{"citizen garbage", EbarimtReceiverCitizen, "not a receiver", false},
+		{"citizen named email", EbarimtReceiverCitizen, "Bat <bat@example.mn>", false},
+		{"unknown type unchecked", "99", "anything", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateEbarimtRequest{PaymentID: "pay-1", EbarimtReceiverType: tt.receiverType, EbarimtReceiver: tt.receiver}
+			err := req.Validate()
+			if tt.valid {
+				if err != nil {
+					t.Errorf("expected valid, got %v", err)
+				}
+				return
+			}
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) || vErr.Field != "ebarimt_receiver" {
+				t.Errorf("expected ebarimt_receiver ValidationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateEbarimt_ValidationErrorSkipsRequest(t *testing.T) {
+	called := false
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer server.Close()
+
+	_, err := client.CreateEbarimt(context.Background(), &CreateEbarimtRequest{
+		PaymentID:           "pay-1",
+		EbarimtReceiverType: EbarimtReceiverOrganization,
+	})
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	if called {
+		t.Error("expected no request for an invalid receiver")
+	}
+
+	_, err = client.CreateEbarimt(context.Background(), nil)
+	if !errors.As(err, &vErr) || vErr.Field != "request" {
+		t.Fatalf("expected a request ValidationError for nil, got %v", err)
+	}
+	if called {
+		t.Error("expected no request for a nil request")
+	}
+}